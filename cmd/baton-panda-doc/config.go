@@ -1,29 +1,59 @@
 package main
 
 import (
+	"github.com/conductorone/baton-panda-doc/pkg/client"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/spf13/viper"
 )
 
 const (
-	apiKey = "api-key"
-	domain = "domain"
+	apiKey                = "api-key"
+	oauthClientID         = "oauth-client-id"
+	oauthClientSecret     = "oauth-client-secret"
+	oauthRefreshToken     = "oauth-refresh-token"
+	oauthRefreshTokenFile = "oauth-refresh-token-file"
+	domain                = "domain"
+	defaultRole           = "default-role"
+	documentStatus        = "document-status"
+	documentModifiedSince = "document-modified-since"
 )
 
 var (
-	apiKeyField = field.StringField(apiKey, field.WithRequired(true), field.WithDescription("PandaDoc account API-Key"))
-	domainField = field.StringField(domain, field.WithRequired(false), field.WithDescription("PandaDoc API domain"), field.WithDefaultValue("us"))
+	apiKeyField                = field.StringField(apiKey, field.WithRequired(false), field.WithIsSecret(true), field.WithDescription("PandaDoc account API-Key"))
+	oauthClientIDField         = field.StringField(oauthClientID, field.WithRequired(false), field.WithDescription("PandaDoc OAuth 2.0 client ID"))
+	oauthClientSecretField     = field.StringField(oauthClientSecret, field.WithRequired(false), field.WithIsSecret(true), field.WithDescription("PandaDoc OAuth 2.0 client secret"))
+	oauthRefreshTokenField     = field.StringField(oauthRefreshToken, field.WithRequired(false), field.WithIsSecret(true), field.WithDescription("PandaDoc OAuth 2.0 refresh token"))
+	oauthRefreshTokenFileField = field.StringField(oauthRefreshTokenFile, field.WithRequired(false), field.WithDescription("File the rotated OAuth 2.0 refresh token is persisted to and loaded from"))
+	domainField                = field.StringField(domain, field.WithRequired(false), field.WithDescription("PandaDoc API domain"), field.WithDefaultValue("us"))
+	defaultRoleField           = field.StringField(defaultRole, field.WithRequired(false), field.WithDescription("Workspace role given to new members and to revoked role holders"), field.WithDefaultValue("Member"))
+	documentStatusField        = field.StringField(documentStatus, field.WithRequired(false), field.WithDescription("Only sync documents with this status, for example document.completed"))
+	documentModifiedSinceField = field.StringField(documentModifiedSince, field.WithRequired(false), field.WithDescription("Only sync documents modified since this RFC 3339 or YYYY-MM-DD date"))
 
 	// ConfigurationFields defines the external configuration required for the
 	// connector to run. Note: these fields can be marked as optional or
 	// required.
-	ConfigurationFields = []field.SchemaField{apiKeyField, domainField}
+	ConfigurationFields = []field.SchemaField{
+		apiKeyField,
+		oauthClientIDField,
+		oauthClientSecretField,
+		oauthRefreshTokenField,
+		oauthRefreshTokenFileField,
+		domainField,
+		defaultRoleField,
+		documentStatusField,
+		documentModifiedSinceField,
+	}
 
 	// FieldRelationships defines relationships between the fields listed in
 	// ConfigurationFields that can be automatically validated. For example, a
 	// username and password can be required together, or an access token can be
 	// marked as mutually exclusive from the username password pair.
-	FieldRelationships = []field.SchemaFieldRelationship{}
+	FieldRelationships = []field.SchemaFieldRelationship{
+		field.FieldsAtLeastOneUsed(apiKeyField, oauthClientIDField),
+		field.FieldsMutuallyExclusive(apiKeyField, oauthClientIDField),
+		field.FieldsRequiredTogether(oauthClientIDField, oauthClientSecretField, oauthRefreshTokenField),
+		field.FieldsDependentOn([]field.SchemaField{oauthRefreshTokenFileField}, []field.SchemaField{oauthClientIDField}),
+	}
 )
 
 // ValidateConfig is run after the configuration is loaded, and should return an
@@ -31,5 +61,6 @@ var (
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
-	return nil
+	_, err := client.NewDocumentFilter(v.GetString(documentStatus), v.GetString(documentModifiedSince))
+	return err
 }
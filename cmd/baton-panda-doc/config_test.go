@@ -13,5 +13,61 @@ func TestConfigs(t *testing.T) {
 		FieldRelationships...,
 	)
 
-	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, []test.TestCase{})
+	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, []test.TestCase{
+		{
+			Configs: map[string]string{},
+			IsValid: false,
+			Message: "missing credentials",
+		},
+		{
+			Configs: map[string]string{
+				apiKey: "1",
+			},
+			IsValid: true,
+			Message: "api key",
+		},
+		{
+			Configs: map[string]string{
+				oauthClientID:     "1",
+				oauthClientSecret: "1",
+				oauthRefreshToken: "1",
+			},
+			IsValid: true,
+			Message: "oauth",
+		},
+		{
+			Configs: map[string]string{
+				oauthClientID: "1",
+			},
+			IsValid: false,
+			Message: "incomplete oauth",
+		},
+		{
+			Configs: map[string]string{
+				apiKey:            "1",
+				oauthClientID:     "1",
+				oauthClientSecret: "1",
+				oauthRefreshToken: "1",
+			},
+			IsValid: false,
+			Message: "api key and oauth",
+		},
+		{
+			Configs: map[string]string{
+				apiKey:                "1",
+				documentStatus:        "document.completed",
+				documentModifiedSince: "2025-01-01",
+			},
+			IsValid: true,
+			Message: "document filter",
+		},
+		{
+			Configs: map[string]string{
+				apiKey:         "1",
+				documentStatus: "completed",
+			},
+			IsValid: false,
+			Message: "unknown document status",
+		},
+	})
 }
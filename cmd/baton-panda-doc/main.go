@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/conductorone/baton-panda-doc/pkg/client"
 	"github.com/conductorone/baton-panda-doc/pkg/connector"
+	"github.com/conductorone/baton-sdk/pkg/cli"
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
@@ -20,12 +22,13 @@ var version = "dev"
 func main() {
 	ctx := context.Background()
 
-	_, cmd, err := config.DefineConfiguration(
+	v, cmd, err := config.DefineConfiguration(
 		ctx,
 		"baton-panda-doc",
 		getConnector,
 		field.Configuration{
-			Fields: ConfigurationFields,
+			Fields:      ConfigurationFields,
+			Constraints: FieldRelationships,
 		},
 	)
 	if err != nil {
@@ -34,6 +37,11 @@ func main() {
 	}
 
 	cmd.Version = version
+	_, err = cli.AddCommand(cmd, v, &webhookConfiguration, webhooksCommand(ctx, v))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
 
 	err = cmd.Execute()
 	if err != nil {
@@ -44,15 +52,26 @@ func main() {
 
 func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, error) {
 	// Get params from Viper
-	pdApiKey := v.GetString(apiKey)
 	pdDomain := v.GetString(domain)
+	pdDefaultRole := v.GetString(defaultRole)
 
 	l := ctxzap.Extract(ctx)
 	if err := ValidateConfig(v); err != nil {
 		return nil, err
 	}
 
-	cb, err := connector.New(ctx, pdDomain, pdApiKey)
+	auth, err := getAuth(v)
+	if err != nil {
+		l.Error("error loading credentials", zap.Error(err))
+		return nil, err
+	}
+
+	documentFilter, err := client.NewDocumentFilter(v.GetString(documentStatus), v.GetString(documentModifiedSince))
+	if err != nil {
+		return nil, err
+	}
+
+	cb, err := connector.New(ctx, pdDomain, pdDefaultRole, documentFilter, auth)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
@@ -64,3 +83,27 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 	}
 	return connector, nil
 }
+
+// getAuth returns the client option for the configured auth mode. In OAuth 2.0 mode a refresh token
+// persisted by a previous run takes precedence over the configured one, which PandaDoc already rotated.
+func getAuth(v *viper.Viper) (client.Option, error) {
+	if v.GetString(oauthClientID) == "" {
+		return client.WithBearerToken(v.GetString(apiKey)), nil
+	}
+
+	pdRefreshToken := v.GetString(oauthRefreshToken)
+	var store client.RefreshTokenStore
+	if path := v.GetString(oauthRefreshTokenFile); path != "" {
+		fileStore := client.FileRefreshTokenStore(path)
+		storedToken, err := fileStore.Load()
+		if err != nil {
+			return nil, err
+		}
+		if storedToken != "" {
+			pdRefreshToken = storedToken
+		}
+		store = fileStore
+	}
+
+	return client.WithOAuth2(v.GetString(oauthClientID), v.GetString(oauthClientSecret), pdRefreshToken, store), nil
+}
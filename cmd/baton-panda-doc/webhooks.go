@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/connector"
+	"github.com/conductorone/baton-sdk/pkg/field"
+	"github.com/conductorone/baton-sdk/pkg/logging"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookListenAddress = "listen-address"
+	webhookSharedKey     = "webhook-shared-key"
+	webhookQueueFile     = "queue-file"
+)
+
+// webhookConfiguration is the part of the connector configuration the webhook listener uses,
+// it looks the owner and the recipients of the documents up in the users of the organization.
+var webhookConfiguration = field.Configuration{
+	Fields: []field.SchemaField{
+		apiKeyField,
+		oauthClientIDField,
+		oauthClientSecretField,
+		oauthRefreshTokenField,
+		oauthRefreshTokenFileField,
+		domainField,
+	},
+	Constraints: FieldRelationships,
+}
+
+// webhooksCommand receives PandaDoc webhook deliveries and appends the access changes they carry to a queue file.
+// The flags are bound to the connector configuration, so they can also be set with BATON_ environment variables.
+func webhooksCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-webhooks",
+		Short: "Receive PandaDoc webhooks and queue the access changes they carry",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := v.BindPFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			runCtx, err := logging.Init(
+				ctx,
+				logging.WithLogFormat(v.GetString("log-format")),
+				logging.WithLogLevel(v.GetString("log-level")),
+			)
+			if err != nil {
+				return err
+			}
+
+			return serveWebhooks(runCtx, v)
+		},
+	}
+
+	cmd.Flags().String(webhookListenAddress, ":8080", "Address the webhook listener binds to ($BATON_LISTEN_ADDRESS)")
+	cmd.Flags().String(webhookSharedKey, "", "Shared key of the PandaDoc webhook, used to verify deliveries ($BATON_WEBHOOK_SHARED_KEY)")
+	cmd.Flags().String(webhookQueueFile, "webhook-events.jsonl", "File the events are appended to ($BATON_QUEUE_FILE)")
+
+	return cmd
+}
+
+func serveWebhooks(ctx context.Context, v *viper.Viper) error {
+	l := ctxzap.Extract(ctx)
+
+	sharedKey := v.GetString(webhookSharedKey)
+	if sharedKey == "" {
+		return fmt.Errorf("baton-panda-doc: %s is required to verify webhook deliveries", webhookSharedKey)
+	}
+
+	auth, err := getAuth(v)
+	if err != nil {
+		return err
+	}
+
+	handler, err := connector.NewWebhookHandler(ctx, v.GetString(domain), auth, sharedKey, connector.NewFileEventQueue(v.GetString(webhookQueueFile)))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              v.GetString(webhookListenAddress),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	l.Info("baton-panda-doc: listening for webhooks", zap.String("address", server.Addr))
+	err = server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("baton-panda-doc: webhook listener failed: %w", err)
+	}
+
+	return nil
+}
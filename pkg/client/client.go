@@ -2,14 +2,19 @@ package client
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"golang.org/x/oauth2"
 )
 
 // Endpoints for PandaDoc API.
@@ -19,6 +24,34 @@ const (
 	// GET Endpoints.
 	allUsers      = "/users"
 	allWorkspaces = "/workspaces"
+	currentMember = "/members/current"
+	allContacts   = "/contacts"
+	allDocuments  = "/documents"
+	allTemplates  = "/templates"
+
+	documentFolders = "/documents/folders"
+	templateFolders = "/templates/folders"
+
+	documentDetails = "/documents/%s/details"
+	templateDetails = "/templates/%s/details"
+
+	apiLogs       = "/logs"
+	apiLogDetails = "/logs/%s"
+
+	webhookSubscriptions = "/webhook-subscriptions"
+
+	// POST Endpoints.
+	createUser          = "/users"
+	createWorkspace     = "/workspaces"
+	deactivateWorkspace = "/workspaces/%s/deactivate"
+	addWorkspaceMember  = "/workspaces/%s/members"
+
+	// PATCH Endpoints.
+	updateUser            = "/users/%s"
+	updateWorkspaceMember = "/workspaces/%s/members/%s"
+
+	// DELETE Endpoints.
+	removeWorkspaceMember = "/workspaces/%s/members/%s"
 )
 
 type PandaDocClient struct {
@@ -26,6 +59,8 @@ type PandaDocClient struct {
 	pandaDocURL string
 	domain      string
 	token       string
+	oauth       *oauthConfig
+	tokenSource oauth2.TokenSource
 }
 
 type Option func(client *PandaDocClient)
@@ -59,9 +94,11 @@ func New(ctx context.Context, opts ...Option) (*PandaDocClient, error) {
 	}
 
 	pDocURL := baseURL
+	tokenURL := oauthTokenURL
 	if pandaDocClient.domain == "eu" {
 		baseURLCopy := baseURL
 		pDocURL = strings.Replace(baseURLCopy, ".com", ".eu", 1)
+		tokenURL = strings.Replace(tokenURL, ".com", ".eu", 1)
 	}
 
 	if !isValidUrl(pDocURL) {
@@ -70,6 +107,9 @@ func New(ctx context.Context, opts ...Option) (*PandaDocClient, error) {
 
 	pandaDocClient.httpClient = cli
 	pandaDocClient.pandaDocURL = pDocURL
+	if pandaDocClient.oauth != nil {
+		pandaDocClient.tokenSource = newTokenSource(ctx, httpClient, tokenURL, pandaDocClient.oauth)
+	}
 
 	return pandaDocClient, nil
 }
@@ -92,14 +132,47 @@ func WithBearerToken(apiToken string) Option {
 	}
 }
 
+// WithOAuth2 authenticates with OAuth 2.0 instead of an API key. Access tokens are refreshed with the
+// refresh token, the refresh tokens PandaDoc rotates are handed to store when it isn't nil.
+func WithOAuth2(clientID, clientSecret, refreshToken string, store RefreshTokenStore) Option {
+	return func(c *PandaDocClient) {
+		c.oauth = &oauthConfig{
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			refreshToken: refreshToken,
+			store:        store,
+		}
+	}
+}
+
 func WithDomain(domain string) Option {
 	return func(c *PandaDocClient) {
 		c.domain = domain
 	}
 }
 
-func (p *PandaDocClient) getToken() string {
-	return p.token
+// authorization returns the Authorization header value, a Bearer access token in OAuth 2.0 mode or the API key.
+func (p *PandaDocClient) authorization() (string, error) {
+	if p.tokenSource != nil {
+		token, err := p.tokenSource.Token()
+		if err != nil {
+			return "", fmt.Errorf("baton-panda-doc: failed to refresh OAuth access token: %w", err)
+		}
+		return "Bearer " + token.AccessToken, nil
+	}
+
+	return "API-Key " + p.token, nil
+}
+
+// APIKeyID identifies the API key the client authenticates with without exposing it, it is empty in OAuth 2.0 mode.
+// It is the start of the SHA-256 of the key, so it changes when the key is rotated.
+func (p *PandaDocClient) APIKeyID() string {
+	if p.oauth != nil || p.token == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(p.token))
+	return hex.EncodeToString(sum[:8])
 }
 
 func (p *PandaDocClient) GetDomain() string {
@@ -116,14 +189,14 @@ func (c *PandaDocClient) getResourcesFromAPI(
 	urlAddress string,
 	res any,
 	reqOpt ...ReqOpt,
-) (string, annotations.Annotations, error) {
+) (annotations.Annotations, error) {
 	_, annotation, err := c.doRequest(ctx, http.MethodGet, urlAddress, &res, nil, reqOpt...)
 
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return "", annotation, nil
+	return annotation, nil
 }
 
 func (c *PandaDocClient) doRequest(
@@ -149,43 +222,60 @@ func (c *PandaDocClient) doRequest(
 		o(urlAddress)
 	}
 
-	req, err := c.httpClient.NewRequest(
-		ctx,
-		method,
-		urlAddress,
-		uhttp.WithAcceptJSONHeader(),
-		uhttp.WithContentTypeJSONHeader(),
-		uhttp.WithHeader("Authorization", "API-Key "+c.getToken()),
-		uhttp.WithJSONBody(body),
-	)
-
+	authorization, err := c.authorization()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	switch method {
-	case http.MethodGet, http.MethodPut, http.MethodPost:
-		var doOptions []uhttp.DoOption
-		if res != nil {
+	var ratelimitData v2.RateLimitDescription
+	for attempt := 0; ; attempt++ {
+		var req *http.Request
+		req, err = c.httpClient.NewRequest(
+			ctx,
+			method,
+			urlAddress,
+			uhttp.WithAcceptJSONHeader(),
+			uhttp.WithContentTypeJSONHeader(),
+			uhttp.WithHeader("Authorization", authorization),
+			uhttp.WithJSONBody(body),
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		doOptions := []uhttp.DoOption{
+			uhttp.WithRatelimitData(&ratelimitData),
+		}
+		if res != nil && method != http.MethodDelete {
 			doOptions = append(doOptions, uhttp.WithResponse(&res))
 		}
 		resp, err = c.httpClient.Do(req, doOptions...)
 
-		if resp != nil {
-			defer resp.Body.Close()
+		if resp == nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRetries {
+			break
 		}
-	case http.MethodDelete:
-		resp, err = c.httpClient.Do(req)
-		if resp != nil {
-			defer resp.Body.Close()
+
+		// The rate limited response is discarded, its body is closed before waiting.
+		resp.Body.Close()
+		if waitErr := wait(ctx, retryDelay(resp.Header, attempt)); waitErr != nil {
+			return nil, nil, waitErr
 		}
 	}
 
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
 	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			return nil, nil, newAPIError(resp)
+		}
 		return nil, nil, err
 	}
 
 	annotation := annotations.Annotations{}
+	annotation.WithRateLimiting(&ratelimitData)
 
 	return resp.Header, annotation, nil
 }
@@ -200,42 +290,365 @@ type WorkspaceResponse struct {
 	Total      int         `json:"total"`
 }
 
+type ContactResponse struct {
+	Contacts []Contact `json:"results"`
+	Total    int       `json:"total"`
+}
+
+// The documents list doesn't report a total, the last page is the first short one.
+type DocumentResponse struct {
+	Documents []Document `json:"results"`
+}
+
+// The templates list doesn't report a total either.
+type TemplateResponse struct {
+	Templates []Template `json:"results"`
+}
+
+type FolderResponse struct {
+	Folders []Folder `json:"results"`
+}
+
+type APILogResponse struct {
+	Logs []APILog `json:"results"`
+}
+
+type WebhookSubscriptionResponse struct {
+	Subscriptions []WebhookSubscription `json:"items"`
+}
+
 func (c *PandaDocClient) ListUsers(ctx context.Context, opts PageOptions) ([]User, string, annotations.Annotations, error) {
-	l := ctxzap.Extract(ctx)
 	var res UserResponse
 
 	queryUrl, err := url.JoinPath(c.pandaDocURL, allUsers)
 	if err != nil {
-		l.Error(fmt.Sprintf("Error creating url: %s", err))
 		return nil, "", nil, err
 	}
 
-	pageToken, annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, WithPage(opts.Page), WithPageLimit(opts.Count))
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, WithPage(opts.Page), WithPageLimit(opts.Count))
 
 	if err != nil {
-		l.Error(fmt.Sprintf("Error getting resources: %s", err))
 		return nil, "", nil, err
 	}
 
-	return res.Users, pageToken, annotation, nil
+	return res.Users, nextPage(opts, len(res.Users), res.Total), annotation, nil
 }
 
 func (c *PandaDocClient) ListWorkspaces(ctx context.Context, opts PageOptions) ([]Workspace, string, annotations.Annotations, error) {
-	l := ctxzap.Extract(ctx)
 	var res WorkspaceResponse
 
 	queryUrl, err := url.JoinPath(c.pandaDocURL, allWorkspaces)
 	if err != nil {
-		l.Error(fmt.Sprintf("Error creating url: %s", err))
 		return nil, "", nil, err
 	}
 
-	pageToken, annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, WithPage(opts.Page), WithPageLimit(opts.Count))
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, WithPage(opts.Page), WithPageLimit(opts.Count))
+
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Workspaces, nextPage(opts, len(res.Workspaces), res.Total), annotation, nil
+}
+
+func (c *PandaDocClient) ListContacts(ctx context.Context, opts PageOptions) ([]Contact, string, annotations.Annotations, error) {
+	var res ContactResponse
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, allContacts)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, WithPage(opts.Page), WithPageLimit(opts.Count))
+
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Contacts, nextPage(opts, len(res.Contacts), res.Total), annotation, nil
+}
+
+func (c *PandaDocClient) ListDocuments(ctx context.Context, opts PageOptions, filter DocumentFilter) ([]Document, string, annotations.Annotations, error) {
+	var res DocumentResponse
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, allDocuments)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	reqOpts := []ReqOpt{WithPage(opts.Page), WithPageLimit(opts.Count)}
+	reqOpts = append(reqOpts, filter.reqOpts()...)
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, reqOpts...)
 
 	if err != nil {
-		l.Error(fmt.Sprintf("Error getting resources: %s", err))
 		return nil, "", nil, err
 	}
 
-	return res.Workspaces, pageToken, annotation, nil
+	return res.Documents, nextPage(opts, len(res.Documents), 0), annotation, nil
+}
+
+func (c *PandaDocClient) GetDocumentDetails(ctx context.Context, documentID string) (*DocumentDetails, annotations.Annotations, error) {
+	var res DocumentDetails
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(documentDetails, documentID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+func (c *PandaDocClient) ListTemplates(ctx context.Context, opts PageOptions) ([]Template, string, annotations.Annotations, error) {
+	var res TemplateResponse
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, allTemplates)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, WithPage(opts.Page), WithPageLimit(opts.Count))
+
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Templates, nextPage(opts, len(res.Templates), 0), annotation, nil
+}
+
+// ListDocumentFolders returns the document folders inside the folder parentID, or the root folders when parentID is empty.
+func (c *PandaDocClient) ListDocumentFolders(ctx context.Context, opts PageOptions, parentID string) ([]Folder, string, annotations.Annotations, error) {
+	return c.listFolders(ctx, documentFolders, opts, parentID)
+}
+
+// ListTemplateFolders returns the template folders inside the folder parentID, or the root folders when parentID is empty.
+func (c *PandaDocClient) ListTemplateFolders(ctx context.Context, opts PageOptions, parentID string) ([]Folder, string, annotations.Annotations, error) {
+	return c.listFolders(ctx, templateFolders, opts, parentID)
+}
+
+func (c *PandaDocClient) listFolders(ctx context.Context, endpoint string, opts PageOptions, parentID string) ([]Folder, string, annotations.Annotations, error) {
+	var res FolderResponse
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, endpoint)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	reqOpts := []ReqOpt{WithPage(opts.Page), WithPageLimit(opts.Count)}
+	if parentID != "" {
+		reqOpts = append(reqOpts, WithQueryParam("parent_uuid", parentID))
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, reqOpts...)
+
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Folders, nextPage(opts, len(res.Folders), 0), annotation, nil
+}
+
+func (c *PandaDocClient) GetTemplateDetails(ctx context.Context, templateID string) (*TemplateDetails, annotations.Annotations, error) {
+	var res TemplateDetails
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(templateDetails, templateID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+// ListAPILogs returns the logs of the write requests made to the API between since and to.
+func (c *PandaDocClient) ListAPILogs(ctx context.Context, opts PageOptions, since, to time.Time) ([]APILog, string, annotations.Annotations, error) {
+	var res APILogResponse
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, apiLogs)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	reqOpts := []ReqOpt{
+		WithPage(opts.Page),
+		WithPageLimit(opts.Count),
+		WithQueryParam("since", since.Format(time.RFC3339)),
+		WithQueryParam("to", to.Format(time.RFC3339)),
+		WithQueryValues("methods", http.MethodPost, http.MethodPatch, http.MethodDelete),
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, reqOpts...)
+
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return res.Logs, nextPage(opts, len(res.Logs), 0), annotation, nil
+}
+
+// GetAPILogDetails returns the log with the bodies of the request and of the response.
+func (c *PandaDocClient) GetAPILogDetails(ctx context.Context, logID string) (*APILogDetails, annotations.Annotations, error) {
+	var res APILogDetails
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(apiLogDetails, logID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+// ListWebhookSubscriptions returns the webhook subscriptions of the workspace the credentials belong to.
+// PandaDoc returns them all at once.
+func (c *PandaDocClient) ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, annotations.Annotations, error) {
+	var res WebhookSubscriptionResponse
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, webhookSubscriptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res.Subscriptions, annotation, nil
+}
+
+func (c *PandaDocClient) AddWorkspaceMember(ctx context.Context, workspaceID string, member WorkspaceMemberRequest) (annotations.Annotations, error) {
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(addWorkspaceMember, workspaceID))
+	if err != nil {
+		return nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPost, queryUrl, nil, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+func (c *PandaDocClient) RemoveWorkspaceMember(ctx context.Context, workspaceID, membershipID string) (annotations.Annotations, error) {
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(removeWorkspaceMember, workspaceID, membershipID))
+	if err != nil {
+		return nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodDelete, queryUrl, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+func (c *PandaDocClient) UpdateWorkspaceMemberRole(ctx context.Context, workspaceID, membershipID, role string) (annotations.Annotations, error) {
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(updateWorkspaceMember, workspaceID, membershipID))
+	if err != nil {
+		return nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPatch, queryUrl, nil, WorkspaceMemberRoleRequest{Role: role})
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+func (c *PandaDocClient) CreateUser(ctx context.Context, user CreateUserRequest) (*User, annotations.Annotations, error) {
+	var res User
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, createUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPost, queryUrl, &res, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+func (c *PandaDocClient) CreateWorkspace(ctx context.Context, workspace CreateWorkspaceRequest) (*Workspace, annotations.Annotations, error) {
+	var res Workspace
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, createWorkspace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPost, queryUrl, &res, workspace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+func (c *PandaDocClient) DeactivateWorkspace(ctx context.Context, workspaceID string) (annotations.Annotations, error) {
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(deactivateWorkspace, workspaceID))
+	if err != nil {
+		return nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPost, queryUrl, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+func (c *PandaDocClient) UpdateUserLicense(ctx context.Context, userID, license string) (annotations.Annotations, error) {
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, fmt.Sprintf(updateUser, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPatch, queryUrl, nil, UserLicenseRequest{License: license})
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+func (c *PandaDocClient) GetCurrentMember(ctx context.Context) (*Member, annotations.Annotations, error) {
+	var res Member
+
+	queryUrl, err := url.JoinPath(c.pandaDocURL, currentMember)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
 }
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The documents list filters statuses by their numeric code.
+var documentStatuses = map[string]int{
+	"document.draft":            0,
+	"document.sent":             1,
+	"document.completed":        2,
+	"document.uploaded":         3,
+	"document.error":            4,
+	"document.viewed":           5,
+	"document.waiting_approval": 6,
+	"document.approved":         7,
+	"document.rejected":         8,
+	"document.waiting_pay":      9,
+	"document.paid":             10,
+	"document.voided":           11,
+	"document.declined":         12,
+	"document.external_review":  13,
+}
+
+// DocumentFilter narrows down the documents listed, the zero value lists every document.
+type DocumentFilter struct {
+	// Status is a document status such as document.completed.
+	Status string
+	// ModifiedFrom only keeps documents modified after it.
+	ModifiedFrom time.Time
+}
+
+// NewDocumentFilter parses the status and the RFC 3339 or YYYY-MM-DD modified since date, both are optional.
+func NewDocumentFilter(status, modifiedSince string) (DocumentFilter, error) {
+	filter := DocumentFilter{
+		Status: status,
+	}
+
+	if _, ok := documentStatuses[status]; status != "" && !ok {
+		return DocumentFilter{}, fmt.Errorf("baton-panda-doc: unknown document status %s", status)
+	}
+
+	if modifiedSince != "" {
+		modifiedFrom, err := time.Parse(time.RFC3339, modifiedSince)
+		if err != nil {
+			modifiedFrom, err = time.Parse(time.DateOnly, modifiedSince)
+		}
+		if err != nil {
+			return DocumentFilter{}, fmt.Errorf("baton-panda-doc: invalid modified since date %s, expected RFC 3339 or YYYY-MM-DD", modifiedSince)
+		}
+		filter.ModifiedFrom = modifiedFrom
+	}
+
+	return filter, nil
+}
+
+func (f DocumentFilter) reqOpts() []ReqOpt {
+	var opts []ReqOpt
+	if code, ok := documentStatuses[f.Status]; ok {
+		opts = append(opts, WithQueryParam("status", strconv.Itoa(code)))
+	}
+	if !f.ModifiedFrom.IsZero() {
+		opts = append(opts, WithQueryParam("modified_from", f.ModifiedFrom.Format(time.RFC3339)))
+	}
+
+	return opts
+}
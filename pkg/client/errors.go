@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/ratelimit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// APIError is a failed PandaDoc API call, decoded from the JSON error body of the response.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Detail     string
+
+	rateLimit *v2.RateLimitDescription
+}
+
+type apiErrorBody struct {
+	Type   string          `json:"type"`
+	Detail json.RawMessage `json:"detail"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("baton-panda-doc: PandaDoc API returned %d", e.StatusCode)
+	if e.Type != "" {
+		msg += fmt.Sprintf(" (%s)", e.Type)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	return msg
+}
+
+// GRPCStatus maps the HTTP status of the error to a gRPC status, so the SDK knows which errors to retry.
+func (e *APIError) GRPCStatus() *status.Status {
+	st := status.New(e.Code(), e.Error())
+	if e.rateLimit != nil {
+		if withDetails, err := st.WithDetails(e.rateLimit); err == nil {
+			return withDetails
+		}
+	}
+
+	return st
+}
+
+func (e *APIError) Code() codes.Code {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	}
+
+	if e.StatusCode >= 500 {
+		return codes.Unavailable
+	}
+
+	return codes.Unknown
+}
+
+// newAPIError builds the APIError of a failed response. PandaDoc usually answers with
+// {"type": "...", "detail": "..."}, detail can also be an object, in which case it is kept as JSON.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err == nil && len(body) > 0 {
+		var errBody apiErrorBody
+		if json.Unmarshal(body, &errBody) == nil {
+			apiErr.Type = errBody.Type
+			if json.Unmarshal(errBody.Detail, &apiErr.Detail) != nil {
+				apiErr.Detail = string(errBody.Detail)
+			}
+		} else {
+			apiErr.Detail = string(body)
+		}
+	}
+
+	if apiErr.Detail == "" {
+		apiErr.Detail = http.StatusText(resp.StatusCode)
+	}
+
+	if rateLimit, err := ratelimit.ExtractRateLimitData(resp.StatusCode, &resp.Header); err == nil {
+		apiErr.rateLimit = rateLimit
+	}
+
+	return apiErr
+}
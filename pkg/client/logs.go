@@ -0,0 +1,30 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeRequest decodes the body of the logged request into v.
+func (d *APILogDetails) DecodeRequest(v any) error {
+	return decodeLogBody(d.RequestBody, v)
+}
+
+// DecodeResponse decodes the body of the logged response into v.
+func (d *APILogDetails) DecodeResponse(v any) error {
+	return decodeLogBody(d.ResponseBody, v)
+}
+
+// decodeLogBody decodes a logged body. Bodies are logged either as JSON or as a string holding the JSON.
+func decodeLogBody(body json.RawMessage, v any) error {
+	if len(body) == 0 || string(body) == "null" {
+		return fmt.Errorf("baton-panda-doc: the log has no body")
+	}
+
+	var encoded string
+	if err := json.Unmarshal(body, &encoded); err == nil {
+		body = json.RawMessage(encoded)
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -1,20 +1,68 @@
 package client
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type User struct {
-	ID                  string `json:"user_id"`
-	Email               string `json:"email"`
-	FirstName           string `json:"first_name,omitempty"`
-	Lastame             string `json:"last_name,omitempty"`
-	Phone               string `json:"phone_number,omitempty"`
-	IsOrganizationOwner bool   `json:"is_organization_owner"`
-	License             string `json:"license"`
-	Workspaces          []struct {
-		Role         string `json:"role"`
-		WorkspaceID  string `json:"workspace_id"`
-		MembershipID string `json:"membership_id"`
-	}
+	ID                  string       `json:"user_id"`
+	Email               string       `json:"email"`
+	FirstName           string       `json:"first_name,omitempty"`
+	Lastame             string       `json:"last_name,omitempty"`
+	Phone               string       `json:"phone_number,omitempty"`
+	IsOrganizationOwner bool         `json:"is_organization_owner"`
+	License             string       `json:"license"`
+	Workspaces          []Membership `json:"workspaces"`
+}
+
+// Member is a user in one of its workspaces.
+type Member struct {
+	UserID        string `json:"user_id"`
+	MembershipID  string `json:"membership_id"`
+	Email         string `json:"email"`
+	FirstName     string `json:"first_name,omitempty"`
+	LastName      string `json:"last_name,omitempty"`
+	Role          string `json:"role"`
+	Workspace     string `json:"workspace"`
+	WorkspaceName string `json:"workspace_name"`
+}
+
+type Membership struct {
+	Role         string `json:"role"`
+	WorkspaceID  string `json:"workspace_id"`
+	MembershipID string `json:"membership_id"`
+}
+
+type CreateUserRequest struct {
+	User       CreateUserDetails      `json:"user"`
+	Workspaces []UserWorkspaceRequest `json:"workspaces"`
+	License    string                 `json:"license,omitempty"`
+}
+
+type CreateUserDetails struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Phone     string `json:"phone_number,omitempty"`
+}
+
+type UserWorkspaceRequest struct {
+	WorkspaceID string `json:"workspace_id"`
+	Role        string `json:"role"`
+}
+
+type UserLicenseRequest struct {
+	License string `json:"license"`
+}
+
+type WorkspaceMemberRequest struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type WorkspaceMemberRoleRequest struct {
+	Role string `json:"role"`
 }
 
 type Workspace struct {
@@ -24,6 +72,105 @@ type Workspace struct {
 	DateCreated time.Time `json:"date_created"`
 }
 
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type Contact struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Company   string `json:"company,omitempty"`
+	JobTitle  string `json:"job_title,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
+type Document struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	DateCreated  time.Time `json:"date_created"`
+	DateModified time.Time `json:"date_modified"`
+}
+
+type DocumentDetails struct {
+	Document
+	CreatedBy  DocumentUser        `json:"created_by"`
+	Recipients []DocumentRecipient `json:"recipients"`
+}
+
+type DocumentUser struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+type DocumentRecipient struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	ContactID string `json:"contact_id,omitempty"`
+}
+
+type Template struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	DateCreated  time.Time `json:"date_created"`
+	DateModified time.Time `json:"date_modified"`
+}
+
+type TemplateDetails struct {
+	Template
+	CreatedBy  DocumentUser `json:"created_by"`
+	SharedWith []Share      `json:"shared_with"`
+}
+
+// Folder holds documents or templates, folders without a parent are at the root of the workspace.
+type Folder struct {
+	ID          string    `json:"uuid"`
+	Name        string    `json:"name"`
+	DateCreated time.Time `json:"date_created"`
+	SharedWith  []Share   `json:"shared_with"`
+}
+
+// Share is a user or a workspace a template or a folder is shared with.
+type Share struct {
+	// Type is either user or workspace.
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Email      string `json:"email,omitempty"`
+	Permission string `json:"permission"`
+}
+
+// APILog is a request made to the PandaDoc API with one of the API keys or OAuth applications of the organization.
+type APILog struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Method      string    `json:"method"`
+	Status      int       `json:"status"`
+	RequestTime time.Time `json:"request_time"`
+}
+
+type APILogDetails struct {
+	APILog
+	RequestBody  json.RawMessage `json:"request_body"`
+	ResponseBody json.RawMessage `json:"response_body"`
+}
+
+// WebhookSubscription sends the events of its triggers to URL. Its shared key is not decoded, it is never synced.
+type WebhookSubscription struct {
+	ID          string   `json:"uuid"`
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Active      bool     `json:"active"`
+	Status      string   `json:"status"`
+	WorkspaceID string   `json:"workspace_id"`
+	Triggers    []string `json:"triggers"`
+}
+
 type Role struct {
 	Description string `json:"description,omitempty"`
 	Name        string `json:"name,omitempty"`
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+const oauthTokenURL = "https://api.pandadoc.com/oauth2/access_token"
+
+// RefreshTokenStore persists the refresh token, PandaDoc issues a new one every time the access token is refreshed.
+type RefreshTokenStore interface {
+	Save(refreshToken string) error
+}
+
+// FileRefreshTokenStore keeps the refresh token in the file at the given path.
+type FileRefreshTokenStore string
+
+// Save replaces the stored refresh token. The token is written to a temporary file next to the store
+// and renamed over it, so a crash while saving never leaves a truncated token behind.
+func (f FileRefreshTokenStore) Save(refreshToken string) error {
+	path := string(f)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(refreshToken)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load returns the stored refresh token, or an empty string if none was stored yet.
+func (f FileRefreshTokenStore) Load() (string, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+type oauthConfig struct {
+	clientID     string
+	clientSecret string
+	refreshToken string
+	store        RefreshTokenStore
+}
+
+// rotatingTokenSource saves the refresh token every time PandaDoc rotates it.
+type rotatingTokenSource struct {
+	src          oauth2.TokenSource
+	store        RefreshTokenStore
+	refreshToken string
+	mutex        sync.Mutex
+}
+
+func (r *rotatingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := r.src.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if token.RefreshToken != "" && token.RefreshToken != r.refreshToken {
+		r.refreshToken = token.RefreshToken
+		if r.store != nil {
+			err = r.store.Save(token.RefreshToken)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return token, nil
+}
+
+// newTokenSource returns a token source that refreshes the access token with the configured refresh token.
+func newTokenSource(ctx context.Context, httpClient *http.Client, tokenURL string, cfg *oauthConfig) oauth2.TokenSource {
+	conf := &oauth2.Config{
+		ClientID:     cfg.clientID,
+		ClientSecret: cfg.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			TokenURL:  tokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	return &rotatingTokenSource{
+		src:          conf.TokenSource(ctx, &oauth2.Token{RefreshToken: cfg.refreshToken}),
+		store:        cfg.store,
+		refreshToken: cfg.refreshToken,
+	}
+}
@@ -23,19 +23,44 @@ type ReqOpt func(reqURL *url.URL)
 
 // count : items per page.
 func WithPageLimit(count int) ReqOpt {
-	if count <= 0 || count > ItemsPerPage {
-		count = ItemsPerPage
-	}
-	return WithQueryParam("count", strconv.Itoa(count))
+	return WithQueryParam("count", strconv.Itoa(pageLimit(count)))
 }
 
 // page: Number for the page (inclusive). The page number starts with 1.
 // If page is 0, first page is assumed.
 func WithPage(page int) ReqOpt {
-	if page == 0 {
-		page = 1
+	return WithQueryParam("page", strconv.Itoa(pageNumber(page)))
+}
+
+func pageLimit(count int) int {
+	if count <= 0 || count > ItemsPerPage {
+		return ItemsPerPage
 	}
-	return WithQueryParam("page", strconv.Itoa(page))
+	return count
+}
+
+func pageNumber(page int) int {
+	if page <= 0 {
+		return 1
+	}
+	return page
+}
+
+// nextPage returns the token of the page after the one requested with opts, or an empty string on the last page.
+// A page shorter than the limit is the last one, otherwise the total reported by the API is used when present.
+func nextPage(opts PageOptions, fetched, total int) string {
+	page := pageNumber(opts.Page)
+	count := pageLimit(opts.Count)
+
+	if fetched < count {
+		return ""
+	}
+
+	if total > 0 && page*count >= total {
+		return ""
+	}
+
+	return strconv.Itoa(page + 1)
 }
 
 func WithQueryParam(key string, value string) ReqOpt {
@@ -45,3 +70,14 @@ func WithQueryParam(key string, value string) ReqOpt {
 		reqURL.RawQuery = q.Encode()
 	}
 }
+
+// WithQueryValues adds every value of a repeated query parameter.
+func WithQueryValues(key string, values ...string) ReqOpt {
+	return func(reqURL *url.URL) {
+		q := reqURL.Query()
+		for _, value := range values {
+			q.Add(key, value)
+		}
+		reqURL.RawQuery = q.Encode()
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PandaDoc answers with 429 when the per-minute limit of an endpoint is reached.
+const (
+	maxRetries     = 3
+	baseRetryDelay = time.Second
+	maxRetryDelay  = time.Minute
+)
+
+// retryDelay returns how long to wait before retrying a rate limited request.
+// Retry-After is honored when present, otherwise the delay grows exponentially with the attempt.
+// A random jitter of up to half the delay is added so concurrent syncs don't retry in lockstep.
+func retryDelay(header http.Header, attempt int) time.Duration {
+	delay := baseRetryDelay << attempt
+
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			delay = time.Until(date)
+		}
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	delay = min(delay, maxRetryDelay)
+
+	return delay + rand.N(delay/2+1)
+}
+
+// wait blocks for the delay or until the context is done.
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+// apiKeyBuilder syncs the API key the connector authenticates with. PandaDoc has no endpoint listing the API keys
+// of a workspace, nor issuing or revoking them, so the other keys are not synced and keys can't be rotated.
+type apiKeyBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	// member is the member the API key belongs to, it is fetched once.
+	member      *client.Member
+	memberMutex sync.Mutex
+}
+
+func (ab *apiKeyBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return ab.resourceType
+}
+
+// List returns the API key of the connector under the workspace it was issued for.
+// Nothing is listed in OAuth 2.0 mode, the connector doesn't use an API key then.
+func (ab *apiKeyBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	keyID := ab.client.APIKeyID()
+	if keyID == "" || parentResourceID == nil || parentResourceID.ResourceType != workspaceResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	member, err := ab.currentMember(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if member.Workspace != parentResourceID.Resource {
+		return nil, "", nil, nil
+	}
+
+	keyResource, err := parseIntoAPIKeyResource(keyID, member, parentResourceID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return []*v2.Resource{keyResource}, "", nil, nil
+}
+
+// This function parses the API key into a secret resource identified by the fingerprint of the key, the key itself
+// is never synced. The key belongs to the user it was issued to, PandaDoc doesn't expose when it was created.
+func parseIntoAPIKeyResource(keyID string, member *client.Member, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	userID := &v2.ResourceId{
+		ResourceType: userResourceType.Id,
+		Resource:     member.UserID,
+	}
+
+	ret, err := resource.NewSecretResource(
+		fmt.Sprintf("%s API key", member.WorkspaceName),
+		apiKeyResourceType,
+		keyID,
+		[]resource.SecretTraitOption{
+			resource.WithSecretIdentityID(userID),
+			resource.WithSecretCreatedByID(userID),
+		},
+		resource.WithDescription(fmt.Sprintf("API key of %s used by the connector", member.Email)),
+		resource.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// API keys grant the access of the user they belong to, they have no entitlements of their own.
+func (ab *apiKeyBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (ab *apiKeyBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newAPIKeyBuilder(client *client.PandaDocClient) *apiKeyBuilder {
+	return &apiKeyBuilder{
+		resourceType: apiKeyResourceType,
+		client:       client,
+	}
+}
+
+func (ab *apiKeyBuilder) currentMember(ctx context.Context) (*client.Member, error) {
+	ab.memberMutex.Lock()
+	defer ab.memberMutex.Unlock()
+
+	if ab.member != nil {
+		return ab.member, nil
+	}
+
+	member, _, err := ab.client.GetCurrentMember(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: failed to get the owner of the API key: %w", err)
+	}
+
+	ab.member = member
+	return ab.member, nil
+}
@@ -0,0 +1,84 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestAPIKeyBuilder_List(t *testing.T) {
+	requests := 0
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"user_id": "testUser01", "email": "user01@example.com", "workspace": "testWorkspace01", "workspace_name": "Sales", "role": "Admin"}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newAPIKeyBuilder(testClient)
+
+	// Without an API key, in OAuth 2.0 mode, nothing is listed.
+	keys, _, _, err := builder.List(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "testWorkspace01"}, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(keys) != 0 || requests != 0 {
+		t.Fatalf("Expected no API key and no request, got %d keys and %d requests", len(keys), requests)
+	}
+
+	client.WithBearerToken("testAPIKey")(testClient)
+
+	keys, _, _, err = builder.List(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "testWorkspace02"}, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected no API key under another workspace, got %d", len(keys))
+	}
+
+	keys, _, _, err = builder.List(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "testWorkspace01"}, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("Expected Count to be 1, got %d", len(keys))
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected the owner of the key to be fetched once, got %d requests", requests)
+	}
+
+	key := keys[0]
+	if key.Id.Resource != testClient.APIKeyID() || key.DisplayName != "Sales API key" {
+		t.Errorf("Unexpected API key: %v", key)
+	}
+
+	if strings.Contains(key.String(), "testAPIKey") {
+		t.Errorf("Expected the API key not to be synced")
+	}
+
+	keyAnnotations := annotations.Annotations(key.Annotations)
+	secretTrait := &v2.SecretTrait{}
+	ok, err := keyAnnotations.Pick(secretTrait)
+	if err != nil || !ok {
+		t.Fatalf("Expected a secret trait, got %v", err)
+	}
+
+	if secretTrait.IdentityId.GetResource() != "testUser01" {
+		t.Errorf("Unexpected identity: got %s, want testUser01", secretTrait.IdentityId.GetResource())
+	}
+}
@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
@@ -9,18 +10,32 @@ import (
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Connector struct {
-	client *client.PandaDocClient
+	client         *client.PandaDocClient
+	directory      *directory
+	defaultRole    string
+	documentFilter client.DocumentFilter
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
 	return []connectorbuilder.ResourceSyncer{
-		newUserBuilder(d.client),
-		newWorkspaceBuilder(d.client),
-		newRolesBuilder(d.client),
+		newOrganizationBuilder(d.client, d.directory),
+		newUserBuilder(d.client, d.directory, d.defaultRole),
+		newWorkspaceBuilder(d.client, d.directory, d.defaultRole),
+		newRolesBuilder(d.client, d.directory, d.defaultRole),
+		newLicenseBuilder(d.client, d.directory),
+		newContactBuilder(d.client, d.directory),
+		newDocumentBuilder(d.client, d.directory, d.documentFilter),
+		newTemplateBuilder(d.client, d.directory),
+		newDocumentFolderBuilder(d.client, d.directory),
+		newTemplateFolderBuilder(d.client, d.directory),
+		newWebhookSubscriptionBuilder(d.client),
+		newAPIKeyBuilder(d.client),
 	}
 }
 
@@ -34,27 +49,67 @@ func (d *Connector) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.R
 func (d *Connector) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	return &v2.ConnectorMetadata{
 		DisplayName: "PandaDoc connector",
-		Description: "Connector to sync users, workspaces, and roles from PandaDoc.",
+		Description: "Connector to sync the organization, users, workspaces, roles, licenses, contacts, documents, templates, folders, webhook subscriptions, and API keys from PandaDoc.",
 	}, nil
 }
 
+// managerRole is the workspace role the credentials must hold, the only role allowed to manage users and members.
+const managerRole = "Admin"
+
 // Validate is called to ensure that the connector is properly configured. It should exercise any API credentials
 // to be sure that they are valid.
+// The credentials must be allowed to list users and workspaces, and belong to an Admin of their workspace.
 func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, error) {
+	// Every sync starts by validating the connector, the users are listed again for it.
+	d.directory.Invalidate()
+
+	member, _, err := d.client.GetCurrentMember(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: failed to validate credentials: %w", err)
+	}
+
+	if member.Role != managerRole {
+		return nil, fmt.Errorf("baton-panda-doc: credentials belong to %s with the %s role, the %s role is required to manage users", member.Email, member.Role, managerRole)
+	}
+
+	_, _, _, err = d.client.ListUsers(ctx, client.PageOptions{Count: 1})
+	if err != nil {
+		return nil, validationError("list users", err)
+	}
+
+	_, _, _, err = d.client.ListWorkspaces(ctx, client.PageOptions{Count: 1})
+	if err != nil {
+		return nil, validationError("list workspaces", err)
+	}
+
 	return nil, nil
 }
 
-// New returns a new instance of the connector.
-func New(ctx context.Context, domain, apiKey string) (*Connector, error) {
+// validationError names the permission the credentials are missing when the probe was denied.
+func validationError(permission string, err error) error {
+	if status.Code(err) == codes.PermissionDenied {
+		return fmt.Errorf("baton-panda-doc: credentials are missing the permission to %s: %w", permission, err)
+	}
+
+	return fmt.Errorf("baton-panda-doc: failed to %s: %w", permission, err)
+}
+
+// New returns a new instance of the connector, auth is either client.WithBearerToken or client.WithOAuth2.
+func New(ctx context.Context, domain, defaultRole string, documentFilter client.DocumentFilter, auth client.Option) (*Connector, error) {
 	pandaDocClient, err := client.New(
 		ctx,
 		client.WithDomain(domain),
-		client.WithBearerToken(apiKey),
+		auth,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &Connector{client: pandaDocClient}, nil
+	return &Connector{
+		client:         pandaDocClient,
+		directory:      newDirectory(pandaDocClient),
+		defaultRole:    defaultRole,
+		documentFilter: documentFilter,
+	}, nil
 }
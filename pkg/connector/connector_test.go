@@ -0,0 +1,120 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+)
+
+func TestConnector_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		role        string
+		usersStatus int
+		expected    string
+	}{
+		{name: "admin", userID: "testUser02", role: "Admin", usersStatus: http.StatusOK},
+		// The role is checked on the member of the credentials, whatever the license of the user.
+		{name: "member", userID: "testUser02", role: "Member", usersStatus: http.StatusOK, expected: "the Admin role is required"},
+		{name: "forbidden", userID: "testUser02", role: "Admin", usersStatus: http.StatusForbidden, expected: "missing the permission to list users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+				resp := &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     make(http.Header),
+				}
+				resp.Header.Set("Content-Type", "application/json")
+
+				switch {
+				case strings.HasSuffix(req.URL.Path, "/members/current"):
+					resp.Body = io.NopCloser(strings.NewReader(`{"user_id": "` + tt.userID + `", "email": "` + tt.userID + `@test.com", "role": "` + tt.role + `"}`))
+				case strings.HasSuffix(req.URL.Path, "/users"):
+					resp.StatusCode = tt.usersStatus
+					resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json")))
+				default:
+					resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_workspaces.json")))
+				}
+
+				return resp, nil
+			})
+
+			ctx := context.Background()
+
+			connector := &Connector{
+				client:      testClient,
+				directory:   newDirectory(testClient),
+				defaultRole: "Member",
+			}
+
+			_, err := connector.Validate(ctx)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestConnector_ValidateStartsNewSnapshot(t *testing.T) {
+	usersRequests := 0
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/members/current"):
+			resp.Body = io.NopCloser(strings.NewReader(`{"user_id": "testUser02", "email": "testUser02@test.com", "role": "Admin"}`))
+		case strings.HasSuffix(req.URL.Path, "/users"):
+			usersRequests++
+			resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json")))
+		default:
+			resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_workspaces.json")))
+		}
+
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	connector := &Connector{
+		client:    testClient,
+		directory: newDirectory(testClient),
+	}
+
+	_, err := connector.directory.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The sync validating the connector sees the users as they are now, not the snapshot of the previous one.
+	_, err = connector.Validate(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	before := usersRequests
+
+	_, err = connector.directory.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if usersRequests != before+1 {
+		t.Errorf("Expected the users to be listed again after Validate, got %d requests", usersRequests-before)
+	}
+}
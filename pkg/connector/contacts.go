@@ -0,0 +1,123 @@
+package connector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+type contactBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	directory    *directory
+}
+
+func (cb *contactBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return cb.resourceType
+}
+
+// List returns the contacts, contacts sharing their email with a user are linked to it through the user_id profile field.
+func (cb *contactBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var resources []*v2.Resource
+
+	bag, pageToken, err := getToken(pToken, contactResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	contacts, nextPage, annotation, err := cb.client.ListContacts(ctx, client.PageOptions{
+		Page:  pageToken,
+		Count: pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	err = bag.Next(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	users, err := cb.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, contact := range contacts {
+		contactResource, err := parseIntoContactResource(&contact, findUserByEmail(users, contact.Email))
+		if err != nil {
+			return nil, "", nil, err
+		}
+		resources = append(resources, contactResource)
+	}
+
+	return resources, nextPageToken, annotation, nil
+}
+
+// This function parses a contact from PandaDoc into a Contact Resource.
+func parseIntoContactResource(contact *client.Contact, user *client.User) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"contact_id": contact.ID,
+		"first_name": contact.FirstName,
+		"last_name":  contact.LastName,
+		"email":      contact.Email,
+		"company":    contact.Company,
+		"job_title":  contact.JobTitle,
+		"phone":      contact.Phone,
+	}
+
+	if user != nil {
+		profile["user_id"] = user.ID
+	}
+
+	contactTraits := []resource.UserTraitOption{
+		resource.WithUserProfile(profile),
+		resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+		resource.WithEmail(contact.Email, true),
+	}
+
+	displayName := strings.TrimSpace(contact.FirstName + " " + contact.LastName)
+	if displayName == "" {
+		displayName = contact.Email
+	}
+
+	ret, err := resource.NewUserResource(
+		displayName,
+		contactResourceType,
+		contact.ID,
+		contactTraits,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Entitlements always returns an empty slice for contacts.
+func (cb *contactBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for contacts since they don't have any entitlements.
+func (cb *contactBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newContactBuilder(client *client.PandaDocClient, directory *directory) *contactBuilder {
+	return &contactBuilder{
+		resourceType: contactResourceType,
+		client:       client,
+		directory:    directory,
+	}
+}
@@ -0,0 +1,57 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestContactBuilder_List(t *testing.T) {
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		mockFile := "mock_users.json"
+		if strings.HasSuffix(req.URL.Path, "/contacts") {
+			mockFile = "mock_contacts.json"
+		}
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile(mockFile))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newContactBuilder(testClient, newDirectory(testClient))
+
+	contacts, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(contacts) != 2 {
+		t.Fatalf("Expected Count to be 2, got %d", len(contacts))
+	}
+
+	// Only the first contact shares its email with a user.
+	expectedUserIDs := []string{"testUser01", ""}
+	for index, contact := range contacts {
+		userTrait, err := resource.GetUserTrait(contact)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		userID, _ := resource.GetProfileStringValue(userTrait.GetProfile(), "user_id")
+		if userID != expectedUserIDs[index] {
+			t.Errorf("Unexpected linked user: got %q, want %q", userID, expectedUserIDs[index])
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package connector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+// directoryTTL is how long the users are kept. Syncs drop the snapshot when they start, the TTL bounds how old
+// the users are in a sync lasting longer and in the webhook listener, which doesn't sync.
+const directoryTTL = 10 * time.Minute
+
+// directory is the snapshot of the users of the organization and their workspace memberships.
+// It is owned by the Connector and shared by every builder, so the users are listed once per sync.
+type directory struct {
+	client    *client.PandaDocClient
+	mutex     sync.Mutex
+	users     []client.User
+	expiresAt time.Time
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+func newDirectory(c *client.PandaDocClient) *directory {
+	return &directory{
+		client: c,
+		ttl:    directoryTTL,
+		now:    time.Now,
+	}
+}
+
+// Users returns every user of the organization. The first caller lists them while the others wait for the result.
+// A failed listing isn't cached, the next caller tries again, and the snapshot is listed again once it expired.
+func (d *directory) Users(ctx context.Context) ([]client.User, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.now().Before(d.expiresAt) {
+		return d.users, nil
+	}
+
+	var users []client.User
+	paginationToken := pagination.Token{
+		Size:  50,
+		Token: "",
+	}
+
+	for {
+		bag, pageToken, err := getToken(&paginationToken, userResourceType)
+		if err != nil {
+			return nil, err
+		}
+		page, nextPageToken, _, err := d.client.ListUsers(ctx, client.PageOptions{
+			Count: paginationToken.Size,
+			Page:  pageToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = bag.Next(nextPageToken)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, page...)
+		nextPageToken, err = bag.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if nextPageToken == "" {
+			break
+		}
+		paginationToken.Token = nextPageToken
+	}
+
+	d.users = users
+	d.expiresAt = d.now().Add(d.ttl)
+
+	return d.users, nil
+}
+
+// Invalidate drops the snapshot after provisioning changed the users or their memberships,
+// the next caller lists the users again.
+func (d *directory) Invalidate() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.users = nil
+	d.expiresAt = time.Time{}
+}
+
+// FindUser returns the user with the given ID, or nil if the user doesn't exist.
+func (d *directory) FindUser(ctx context.Context, userID string) (*client.User, error) {
+	users, err := d.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user.ID == userID {
+			userCopy := user
+			return &userCopy, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findUserByEmail returns the user with the given email, emails are compared case-insensitively.
+func findUserByEmail(users []client.User, email string) *client.User {
+	if email == "" {
+		return nil
+	}
+
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			userCopy := user
+			return &userCopy
+		}
+	}
+
+	return nil
+}
+
+// Membership returns the workspace membership of the user, or nil if the user is not a member of the workspace.
+func (d *directory) Membership(ctx context.Context, workspaceID, userID string) (*client.Membership, error) {
+	user, err := d.FindUser(ctx, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	for _, workspace := range user.Workspaces {
+		if workspace.WorkspaceID == workspaceID {
+			membership := workspace
+			return &membership, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FindMember returns the user holding the membership of the workspace, or nil if there is none.
+func (d *directory) FindMember(ctx context.Context, workspaceID, membershipID string) (*client.User, error) {
+	users, err := d.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		for _, workspace := range user.Workspaces {
+			if workspace.WorkspaceID == workspaceID && workspace.MembershipID == membershipID {
+				userCopy := user
+				return &userCopy, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,104 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	"github.com/conductorone/baton-panda-doc/test"
+)
+
+func TestDirectory_UsersListedOnce(t *testing.T) {
+	users := make([]any, 0, 120)
+	for i := range 120 {
+		users = append(users, client.User{
+			ID:    fmt.Sprintf("testUser%03d", i),
+			Email: fmt.Sprintf("testUser%03d@test.com", i),
+		})
+	}
+
+	requests := 0
+	testClient := test.NewMockTestClient(test.PagedRoundTrip(users, &requests))
+
+	ctx := context.Background()
+
+	dir := newDirectory(testClient)
+
+	// Builders are synced concurrently, they must share a single listing.
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			listed, err := dir.Users(ctx)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			if len(listed) != len(users) {
+				t.Errorf("Expected Count to be %d, got %d", len(users), len(listed))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+
+	user, err := dir.FindUser(ctx, "testUser050")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user == nil || user.Email != "testUser050@test.com" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	// Provisioning invalidates the snapshot, the users are listed again.
+	dir.Invalidate()
+	_, err = dir.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 6 {
+		t.Errorf("Expected 6 requests, got %d", requests)
+	}
+}
+
+func TestDirectory_UsersExpire(t *testing.T) {
+	users := []any{
+		client.User{ID: "testUser01", Email: "testUser01@test.com"},
+	}
+
+	requests := 0
+	testClient := test.NewMockTestClient(test.PagedRoundTrip(users, &requests))
+
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir := newDirectory(testClient)
+	dir.now = func() time.Time { return now }
+
+	for range 2 {
+		_, err := dir.Users(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request within a sync, got %d", requests)
+	}
+
+	// The next sync of a long running connector lists the users again.
+	now = now.Add(directoryTTL)
+	_, err := dir.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests once the snapshot expired, got %d", requests)
+	}
+}
@@ -0,0 +1,176 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	documentOwner     = "owner"
+	documentRecipient = "recipient"
+)
+
+type documentBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	filter       client.DocumentFilter
+	directory    *directory
+}
+
+func (db *documentBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return db.resourceType
+}
+
+// List returns the documents matching the configured filter, built from the list payload alone.
+// The details are only fetched when the grants of a document are built.
+func (db *documentBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var resources []*v2.Resource
+
+	bag, pageToken, err := getToken(pToken, documentResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	documents, nextPage, annotation, err := db.client.ListDocuments(ctx, client.PageOptions{
+		Page:  pageToken,
+		Count: pToken.Size,
+	}, db.filter)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	err = bag.Next(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, document := range documents {
+		documentResource, err := parseIntoDocumentResource(&document)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		resources = append(resources, documentResource)
+	}
+
+	return resources, nextPageToken, annotation, nil
+}
+
+// This function parses a document from PandaDoc into a Document Resource.
+// Resources without traits carry no profile, the status and dates go in the description.
+// The template the document was created from is not synced, only the details of each document carry it.
+func parseIntoDocumentResource(document *client.Document) (*v2.Resource, error) {
+	description := fmt.Sprintf(
+		"Status %s, created %s, modified %s",
+		document.Status,
+		document.DateCreated.Format(time.RFC3339),
+		document.DateModified.Format(time.RFC3339),
+	)
+
+	ret, err := resource.NewResource(
+		document.Name,
+		documentResourceType,
+		document.ID,
+		resource.WithDescription(description),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (db *documentBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	ownerOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Owner of the %s document", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, documentOwner)),
+	}
+
+	recipientOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType, contactResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Recipient of the %s document", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, documentRecipient)),
+	}
+
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(resource, documentOwner, ownerOptions...),
+		entitlement.NewPermissionEntitlement(resource, documentRecipient, recipientOptions...),
+	}, "", nil, nil
+}
+
+// Grants returns the owner grant and a grant for every recipient. Recipients sharing their email
+// with a user are granted as that user, the others as their contact.
+func (db *documentBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var grants []*v2.Grant
+
+	details, annotation, err := db.client.GetDocumentDetails(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	users, err := db.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if owner := documentOwnerPrincipal(users, &details.CreatedBy); owner != nil {
+		grants = append(grants, grant.NewGrant(resource, documentOwner, owner))
+	}
+
+	for _, recipient := range details.Recipients {
+		if principal := documentRecipientPrincipal(users, &recipient); principal != nil {
+			grants = append(grants, grant.NewGrant(resource, documentRecipient, principal))
+		}
+	}
+
+	return grants, "", annotation, nil
+}
+
+// documentOwnerPrincipal returns the user who created the document, found by email and falling back to its ID.
+func documentOwnerPrincipal(users []client.User, createdBy *client.DocumentUser) *v2.ResourceId {
+	ownerID := createdBy.ID
+	if owner := findUserByEmail(users, createdBy.Email); owner != nil {
+		ownerID = owner.ID
+	}
+	if ownerID == "" {
+		return nil
+	}
+
+	return &v2.ResourceId{ResourceType: userResourceType.Id, Resource: ownerID}
+}
+
+// documentRecipientPrincipal returns the user sharing the email of the recipient, or its contact.
+// It returns nil for recipients that are neither.
+func documentRecipientPrincipal(users []client.User, recipient *client.DocumentRecipient) *v2.ResourceId {
+	switch user := findUserByEmail(users, recipient.Email); {
+	case user != nil:
+		return &v2.ResourceId{ResourceType: userResourceType.Id, Resource: user.ID}
+	case recipient.ContactID != "":
+		return &v2.ResourceId{ResourceType: contactResourceType.Id, Resource: recipient.ContactID}
+	default:
+		return nil
+	}
+}
+
+func newDocumentBuilder(c *client.PandaDocClient, directory *directory, filter client.DocumentFilter) *documentBuilder {
+	return &documentBuilder{
+		resourceType: documentResourceType,
+		client:       c,
+		directory:    directory,
+		filter:       filter,
+	}
+}
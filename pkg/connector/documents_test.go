@@ -0,0 +1,80 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	"github.com/conductorone/baton-panda-doc/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestDocumentBuilder_Grants(t *testing.T) {
+	requests := 0
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+
+		var mockFile string
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/details"):
+			mockFile = "mock_document_details.json"
+		case strings.HasSuffix(req.URL.Path, "/documents"):
+			mockFile = "mock_documents.json"
+		default:
+			mockFile = "mock_users.json"
+		}
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile(mockFile))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newDocumentBuilder(testClient, newDirectory(testClient), client.DocumentFilter{})
+
+	documents, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(documents) != 1 {
+		t.Fatalf("Expected Count to be 1, got %d", len(documents))
+	}
+
+	// Listing doesn't fetch the details of the documents.
+	if requests != 1 {
+		t.Errorf("Expected 1 request to list, got %d", requests)
+	}
+
+	grants, _, _, err := builder.Grants(ctx, documents[0], &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"document:testDocument01:owner:user:testUser02",
+		"document:testDocument01:recipient:user:testUser01",
+		"document:testDocument01:recipient:contact:testContact02",
+	}
+	if len(grants) != len(expected) {
+		t.Fatalf("Expected %d grants, got %d", len(expected), len(grants))
+	}
+	for index, documentGrant := range grants {
+		if documentGrant.Id != expected[index] {
+			t.Errorf("Unexpected grant: got %s, want %s", documentGrant.Id, expected[index])
+		}
+	}
+
+	// Documents, details and users.
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
@@ -0,0 +1,280 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Paths of the logged requests that change access, the IDs in the path are captured.
+var (
+	createUserPath       = regexp.MustCompile(`/users$`)
+	updateUserPath       = regexp.MustCompile(`/users/([^/]+)$`)
+	workspaceMembersPath = regexp.MustCompile(`/workspaces/([^/]+)/members$`)
+	workspaceMemberPath  = regexp.MustCompile(`/workspaces/([^/]+)/members/([^/]+)$`)
+)
+
+// eventCursor is the position of the event stream. Logs are read in windows ending at To, so logs
+// arriving while a window is paged don't shift its pages. Once a window is read the next one starts at its end.
+type eventCursor struct {
+	Since time.Time `json:"since"`
+	To    time.Time `json:"to"`
+	Page  int       `json:"page,omitempty"`
+}
+
+// ListEvents reads the PandaDoc API logs from earliestEvent and returns the access changes made through the API:
+// users created, workspace members added or given another role, and license changes.
+// Removed members are not reported, the removal is logged with the membership ID alone and no body.
+// Changes made from the PandaDoc app are not logged, a full sync still picks them up.
+func (d *Connector) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	cursor, err := parseEventCursor(pToken.Cursor, earliestEvent)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	logs, nextPage, annotation, err := d.client.ListAPILogs(ctx, client.PageOptions{
+		Count: pToken.Size,
+		Page:  cursor.Page,
+	}, cursor.Since, cursor.To)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("baton-panda-doc: failed to list API logs: %w", err)
+	}
+
+	var events []*v2.Event
+	for _, log := range logs {
+		logEvents, err := d.logEvents(ctx, log)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		events = append(events, logEvents...)
+	}
+
+	next := eventCursor{Since: cursor.To}
+	if nextPage != "" {
+		next = eventCursor{Since: cursor.Since, To: cursor.To, Page: max(cursor.Page, 1) + 1}
+	}
+
+	nextCursor, err := json.Marshal(next)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return events, &pagination.StreamState{
+		Cursor:  string(nextCursor),
+		HasMore: nextPage != "",
+	}, annotation, nil
+}
+
+// parseEventCursor returns the window to read. Without a cursor the stream starts at earliestEvent,
+// a cursor without an end starts a new window ending now.
+func parseEventCursor(rawCursor string, earliestEvent *timestamppb.Timestamp) (*eventCursor, error) {
+	cursor := &eventCursor{}
+	if rawCursor != "" {
+		err := json.Unmarshal([]byte(rawCursor), cursor)
+		if err != nil {
+			return nil, fmt.Errorf("baton-panda-doc: invalid event cursor: %w", err)
+		}
+	} else if earliestEvent != nil {
+		cursor.Since = earliestEvent.AsTime()
+	}
+
+	if cursor.To.IsZero() {
+		cursor.To = time.Now().UTC()
+		if cursor.Since.IsZero() {
+			cursor.Since = cursor.To
+		}
+	}
+
+	return cursor, nil
+}
+
+// logEvents returns the events of a logged request, requests that didn't change access have none.
+func (d *Connector) logEvents(ctx context.Context, log client.APILog) ([]*v2.Event, error) {
+	l := ctxzap.Extract(ctx)
+
+	if log.Status < http.StatusOK || log.Status >= http.StatusMultipleChoices {
+		return nil, nil
+	}
+
+	logURL, err := url.Parse(log.URL)
+	if err != nil {
+		l.Debug("baton-panda-doc: skipping log with an invalid url", zap.String("log_id", log.ID), zap.Error(err))
+		return nil, nil
+	}
+	path := logURL.Path
+
+	var handle func(details *client.APILogDetails) ([]*v2.Event, error)
+	switch {
+	case log.Method == http.MethodPost && createUserPath.MatchString(path):
+		handle = userCreatedEvents
+	case log.Method == http.MethodPatch && updateUserPath.MatchString(path):
+		userID := updateUserPath.FindStringSubmatch(path)[1]
+		handle = func(details *client.APILogDetails) ([]*v2.Event, error) {
+			return licenseChangedEvents(details, userID)
+		}
+	case log.Method == http.MethodPost && workspaceMembersPath.MatchString(path):
+		workspaceID := workspaceMembersPath.FindStringSubmatch(path)[1]
+		handle = func(details *client.APILogDetails) ([]*v2.Event, error) {
+			return memberAddedEvents(details, workspaceID)
+		}
+	case log.Method == http.MethodPatch && workspaceMemberPath.MatchString(path):
+		workspaceID := workspaceMemberPath.FindStringSubmatch(path)[1]
+		handle = func(details *client.APILogDetails) ([]*v2.Event, error) {
+			return roleChangedEvents(details, workspaceID)
+		}
+	default:
+		return nil, nil
+	}
+
+	details, _, err := d.client.GetAPILogDetails(ctx, log.ID)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: failed to get API log %s: %w", log.ID, err)
+	}
+
+	events, err := handle(details)
+	if err != nil {
+		l.Debug("baton-panda-doc: skipping log with an unexpected body", zap.String("log_id", log.ID), zap.Error(err))
+		return nil, nil
+	}
+
+	return events, nil
+}
+
+// userCreatedEvents grants the created user its role in each of its workspaces and its license.
+func userCreatedEvents(details *client.APILogDetails) ([]*v2.Event, error) {
+	var request client.CreateUserRequest
+	err := details.DecodeRequest(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	var user client.User
+	err = details.DecodeResponse(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*v2.Event
+	for _, workspace := range request.Workspaces {
+		events = append(events, roleGrantEvent(&details.APILog, len(events), workspace.WorkspaceID, workspace.Role, user.ID))
+	}
+	if request.License != "" {
+		events = append(events, licenseGrantEvent(&details.APILog, len(events), request.License, user.ID))
+	}
+
+	return events, nil
+}
+
+func licenseChangedEvents(details *client.APILogDetails, userID string) ([]*v2.Event, error) {
+	var request client.UserLicenseRequest
+	err := details.DecodeRequest(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.License == "" {
+		return nil, nil
+	}
+
+	return []*v2.Event{licenseGrantEvent(&details.APILog, 0, request.License, userID)}, nil
+}
+
+func memberAddedEvents(details *client.APILogDetails, workspaceID string) ([]*v2.Event, error) {
+	var request client.WorkspaceMemberRequest
+	err := details.DecodeRequest(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*v2.Event{roleGrantEvent(&details.APILog, 0, workspaceID, request.Role, request.UserID)}, nil
+}
+
+// loggedMember returns the member the logged response was about. The request only carries the membership ID,
+// the user and its role are read from the response.
+func loggedMember(details *client.APILogDetails) (*client.Member, error) {
+	var member client.Member
+	err := details.DecodeResponse(&member)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.UserID == "" || member.Role == "" {
+		return nil, fmt.Errorf("baton-panda-doc: the log doesn't carry the member")
+	}
+
+	return &member, nil
+}
+
+// roleChangedEvents grants the new role to the member. The role held before isn't logged,
+// it is revoked by the next full sync.
+func roleChangedEvents(details *client.APILogDetails, workspaceID string) ([]*v2.Event, error) {
+	member, err := loggedMember(details)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*v2.Event{roleGrantEvent(&details.APILog, 0, workspaceID, member.Role, member.UserID)}, nil
+}
+
+// roleGrantEvent grants the role in the workspace, workspace membership is expanded from it.
+// Logs can hold several events, index tells them apart.
+func roleGrantEvent(log *client.APILog, index int, workspaceID, role, userID string) *v2.Event {
+	roleResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: roleResourceType.Id,
+			Resource:     roleID(workspaceID, role),
+		},
+	}
+
+	return &v2.Event{
+		Id:         fmt.Sprintf("%s:%d", log.ID, index),
+		OccurredAt: timestamppb.New(log.RequestTime),
+		Event: &v2.Event_GrantEvent{
+			GrantEvent: &v2.GrantEvent{
+				Grant: grant.NewGrant(roleResource, roleAssignment, &v2.ResourceId{
+					ResourceType: userResourceType.Id,
+					Resource:     userID,
+				}),
+			},
+		},
+	}
+}
+
+func licenseGrantEvent(log *client.APILog, index int, license, userID string) *v2.Event {
+	licenseResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: licenseResourceType.Id,
+			Resource:     license,
+		},
+	}
+
+	return &v2.Event{
+		Id:         fmt.Sprintf("%s:%d", log.ID, index),
+		OccurredAt: timestamppb.New(log.RequestTime),
+		Event: &v2.Event_GrantEvent{
+			GrantEvent: &v2.GrantEvent{
+				Grant: grant.NewGrant(licenseResource, licenseAssignment, &v2.ResourceId{
+					ResourceType: userResourceType.Id,
+					Resource:     userID,
+				}),
+			},
+		},
+	}
+}
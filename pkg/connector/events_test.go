@@ -0,0 +1,104 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestConnector_ListEvents(t *testing.T) {
+	var since string
+	var usersListed bool
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/logs"):
+			since = req.URL.Query().Get("since")
+			resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_api_logs.json")))
+		case strings.HasSuffix(req.URL.Path, "/logs/testLog01"):
+			// Bodies can be logged as strings holding the JSON.
+			resp.Body = io.NopCloser(strings.NewReader(`{"id": "testLog01", "request_body": "{\"user_id\": \"testUser02\", \"role\": \"Manager\"}"}`))
+		case strings.HasSuffix(req.URL.Path, "/logs/testLog02"):
+			resp.Body = io.NopCloser(strings.NewReader(`{"id": "testLog02", "request_body": {"role": "Member"}, "response_body": {"user_id": "testUser01", "membership_id": "testMember02", "role": "Member"}}`))
+		case strings.HasSuffix(req.URL.Path, "/logs/testLog03"):
+			// Removals answer 204 and only log the membership ID, they are not read.
+			t.Errorf("Unexpected request for the removal log")
+			resp.Body = io.NopCloser(strings.NewReader(`{"id": "testLog03"}`))
+		default:
+			usersListed = true
+			resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json")))
+		}
+
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	connector := &Connector{
+		client:    testClient,
+		directory: newDirectory(testClient),
+	}
+
+	earliestEvent := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	events, state, _, err := connector.ListEvents(ctx, timestamppb.New(earliestEvent), &pagination.StreamToken{Size: 50})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if since != earliestEvent.Format(time.RFC3339) {
+		t.Errorf("Unexpected since: got %s, want %s", since, earliestEvent.Format(time.RFC3339))
+	}
+
+	expected := []string{
+		"grant role:TestWorkspace01:Manager:assigned:user:testUser02",
+		"grant role:TestWorkspace02:Member:assigned:user:testUser01",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for index, event := range events {
+		var got string
+		switch e := event.GetEvent().(type) {
+		case *v2.Event_GrantEvent:
+			got = "grant " + e.GrantEvent.GetGrant().GetId()
+		case *v2.Event_RevokeEvent:
+			principal := e.RevokeEvent.GetPrincipal().GetId()
+			got = "revoke " + e.RevokeEvent.GetEntitlement().GetId() + " " + principal.GetResourceType() + ":" + principal.GetResource()
+		}
+		if got != expected[index] {
+			t.Errorf("Unexpected event: got %s, want %s", got, expected[index])
+		}
+	}
+
+	// The members are read from the logs, the users snapshot already reflects the changes.
+	if usersListed {
+		t.Error("Expected the users not to be listed")
+	}
+
+	// The window was read in one page, the next one starts where it ended.
+	if state.HasMore {
+		t.Error("Expected no more events")
+	}
+	cursor := eventCursor{}
+	err = json.Unmarshal([]byte(state.Cursor), &cursor)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cursor.Since.IsZero() || !cursor.To.IsZero() {
+		t.Errorf("Unexpected cursor: %s", state.Cursor)
+	}
+}
@@ -0,0 +1,229 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// folderSharesKey is the field of the folder annotation holding its shares.
+const folderSharesKey = "shared_with"
+
+type listFoldersFunc func(ctx context.Context, opts client.PageOptions, parentID string) ([]client.Folder, string, annotations.Annotations, error)
+
+// folderBuilder syncs either the document or the template folders, depending on its resource type.
+type folderBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	listFolders  listFoldersFunc
+	// workspaceID is the workspace the credentials belong to, the only one whose folders can be listed.
+	workspaceID    string
+	workspaceMutex sync.Mutex
+	directory      *directory
+}
+
+func (fb *folderBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return fb.resourceType
+}
+
+// List returns the root folders under the workspace of the credentials and the subfolders under their parent folder.
+func (fb *folderBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var resources []*v2.Resource
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	var parentFolderID string
+	switch parentResourceID.ResourceType {
+	case workspaceResourceType.Id:
+		workspaceID, err := fb.currentWorkspace(ctx)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if parentResourceID.Resource != workspaceID {
+			return nil, "", nil, nil
+		}
+	case fb.resourceType.Id:
+		parentFolderID = parentResourceID.Resource
+	default:
+		return nil, "", nil, nil
+	}
+
+	bag, pageToken, err := getToken(pToken, fb.resourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	folders, nextPage, annotation, err := fb.listFolders(ctx, client.PageOptions{
+		Page:  pageToken,
+		Count: pToken.Size,
+	}, parentFolderID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	err = bag.Next(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, folder := range folders {
+		folderResource, err := parseIntoFolderResource(&folder, fb.resourceType, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		resources = append(resources, folderResource)
+	}
+
+	return resources, nextPageToken, annotation, nil
+}
+
+// This function parses a folder from PandaDoc into a Folder Resource, subfolders are listed under it.
+// PandaDoc only returns the shares of a folder when listing its parent, they are kept on the resource for its grants.
+func parseIntoFolderResource(folder *client.Folder, resourceType *v2.ResourceType, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	shares, err := folderSharesAnnotation(folder.SharedWith)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := resource.NewResource(
+		folder.Name,
+		resourceType,
+		folder.ID,
+		resource.WithDescription(fmt.Sprintf("Created %s", folder.DateCreated.Format(time.RFC3339))),
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceType.Id}, shares),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (fb *folderBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	editorOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType, workspaceResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Can edit the content of the %s folder", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, shareEditor)),
+	}
+
+	viewerOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType, workspaceResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Can view the content of the %s folder", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, shareViewer)),
+	}
+
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(resource, shareEditor, editorOptions...),
+		entitlement.NewPermissionEntitlement(resource, shareViewer, viewerOptions...),
+	}, "", nil, nil
+}
+
+// Grants returns a grant for every user and workspace the folder is explicitly shared with.
+func (fb *folderBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	shares, err := folderShares(resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(shares) == 0 {
+		return nil, "", nil, nil
+	}
+
+	users, err := fb.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return shareGrants(resource, shares, users), "", nil, nil
+}
+
+func newDocumentFolderBuilder(c *client.PandaDocClient, directory *directory) *folderBuilder {
+	return &folderBuilder{
+		resourceType: documentFolderResourceType,
+		client:       c,
+		directory:    directory,
+		listFolders:  c.ListDocumentFolders,
+	}
+}
+
+func newTemplateFolderBuilder(c *client.PandaDocClient, directory *directory) *folderBuilder {
+	return &folderBuilder{
+		resourceType: templateFolderResourceType,
+		client:       c,
+		directory:    directory,
+		listFolders:  c.ListTemplateFolders,
+	}
+}
+
+// currentWorkspace returns the ID of the workspace the credentials belong to, it is fetched once.
+func (fb *folderBuilder) currentWorkspace(ctx context.Context) (string, error) {
+	fb.workspaceMutex.Lock()
+	defer fb.workspaceMutex.Unlock()
+
+	if fb.workspaceID != "" {
+		return fb.workspaceID, nil
+	}
+
+	member, _, err := fb.client.GetCurrentMember(ctx)
+	if err != nil {
+		return "", fmt.Errorf("baton-panda-doc: failed to get the workspace of the credentials: %w", err)
+	}
+
+	fb.workspaceID = member.Workspace
+	return fb.workspaceID, nil
+}
+
+// folderSharesAnnotation encodes the shares of a folder into an annotation of its resource.
+func folderSharesAnnotation(shares []client.Share) (*structpb.Struct, error) {
+	encoded, err := json.Marshal(map[string]interface{}{folderSharesKey: shares})
+	if err != nil {
+		return nil, err
+	}
+
+	annotation := &structpb.Struct{}
+	err = annotation.UnmarshalJSON(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+// folderShares decodes the shares kept on the folder resource when it was listed.
+func folderShares(folder *v2.Resource) ([]client.Share, error) {
+	annotation := &structpb.Struct{}
+	folderAnnotations := annotations.Annotations(folder.Annotations)
+	ok, err := folderAnnotations.Pick(annotation)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	encoded, err := annotation.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded map[string][]client.Share
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: invalid shares on folder %s: %w", folder.Id.Resource, err)
+	}
+
+	return decoded[folderSharesKey], nil
+}
@@ -0,0 +1,96 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestFolderBuilder_List(t *testing.T) {
+	var parents []string
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/members/current"):
+			resp.Body = io.NopCloser(strings.NewReader(`{"user_id": "testUser01", "workspace": "testWorkspace01", "role": "Admin"}`))
+		case strings.HasSuffix(req.URL.Path, "/documents/folders"):
+			parents = append(parents, req.URL.Query().Get("parent_uuid"))
+			resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_folders.json")))
+		default:
+			resp.Body = io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json")))
+		}
+
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newDocumentFolderBuilder(testClient, newDirectory(testClient))
+
+	// Only the workspace of the credentials has folders.
+	folders, _, _, err := builder.List(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "testWorkspace02"}, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(folders) != 0 {
+		t.Fatalf("Expected no folders in another workspace, got %d", len(folders))
+	}
+
+	workspaceID := &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "testWorkspace01"}
+	folders, _, _, err = builder.List(ctx, workspaceID, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(folders) != 2 {
+		t.Fatalf("Expected Count to be 2, got %d", len(folders))
+	}
+	if folders[0].ParentResourceId.Resource != "testWorkspace01" {
+		t.Errorf("Expected the workspace as parent, got %s", folders[0].ParentResourceId.Resource)
+	}
+
+	subfolders, _, _, err := builder.List(ctx, folders[0].Id, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if subfolders[0].ParentResourceId.Resource != "testFolder01" {
+		t.Errorf("Expected the folder as parent, got %s", subfolders[0].ParentResourceId.Resource)
+	}
+
+	if len(parents) != 2 || parents[0] != "" || parents[1] != "testFolder01" {
+		t.Errorf("Unexpected parent_uuid parameters: %v", parents)
+	}
+
+	// The shares are kept on the resource, the grants don't list the folders again.
+	parents = nil
+	grants, _, _, err := newDocumentFolderBuilder(testClient, newDirectory(testClient)).Grants(ctx, folders[0], &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(parents) != 0 {
+		t.Errorf("Expected no folder listing for the grants, got %v", parents)
+	}
+
+	expected := []string{
+		"document_folder:testFolder01:editor:user:testUser02",
+		"document_folder:testFolder01:viewer:workspace:testWorkspace01",
+	}
+	if len(grants) != len(expected) {
+		t.Fatalf("Expected %d grants, got %d", len(expected), len(grants))
+	}
+	for index, folderGrant := range grants {
+		if folderGrant.Id != expected[index] {
+			t.Errorf("Unexpected grant: got %s, want %s", folderGrant.Id, expected[index])
+		}
+	}
+}
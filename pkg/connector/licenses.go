@@ -0,0 +1,196 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const licenseAssignment = "assigned"
+
+type licenseBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	directory    *directory
+}
+
+func (lb *licenseBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return lb.resourceType
+}
+
+// There is no endpoint for Licenses.
+// The known licenses are always listed, any other license is retrieved from the users list.
+func (lb *licenseBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var rv []*v2.Resource
+
+	users, err := lb.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, license := range userLicenses(users) {
+		licenseResource, err := parseIntoLicenseResource(license, assignedSeats(users, license))
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rv = append(rv, licenseResource)
+	}
+
+	return rv, "", nil, nil
+}
+
+// The PandaDoc API doesn't expose the purchased seats, the profile only carries the seats in use.
+func parseIntoLicenseResource(license string, assignedSeats int) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":             license,
+		"name":           license,
+		"assigned_seats": assignedSeats,
+	}
+
+	licenseTraits := []rs.RoleTraitOption{
+		rs.WithRoleProfile(profile),
+	}
+
+	ret, err := rs.NewRoleResource(license, licenseResourceType, license, licenseTraits)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (lb *licenseBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	assigmentOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Assigned the %s license", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s license", resource.DisplayName)),
+	}
+
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(resource, licenseAssignment, assigmentOptions...),
+	}, "", nil, nil
+}
+
+func (lb *licenseBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var grants []*v2.Grant
+
+	users, err := lb.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, user := range users {
+		if user.License == resource.Id.Resource {
+			userResource, _ := parseIntoUserResource(ctx, &user, nil)
+			grants = append(grants, grant.NewGrant(resource, licenseAssignment, userResource))
+		}
+	}
+
+	return grants, "", nil, nil
+}
+
+// Grant changes the license of the user, the user loses its previous license.
+// Only the known licenses and the licenses already held by a user can be granted.
+func (lb *licenseBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"baton-panda-doc: only users can be granted licenses",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, nil, fmt.Errorf("baton-panda-doc: only users can be granted licenses")
+	}
+
+	license := entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	users, err := lb.directory.Users(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !slices.Contains(userLicenses(users), license) {
+		return nil, nil, fmt.Errorf("baton-panda-doc: unknown license %s", license)
+	}
+
+	user, err := lb.directory.FindUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil {
+		return nil, nil, fmt.Errorf("baton-panda-doc: user %s not found", userID)
+	}
+
+	if user.License == license {
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	annotation, err := lb.client.UpdateUserLicense(ctx, userID, license)
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-panda-doc: failed to change license of user %s to %s: %w", userID, license, err)
+	}
+	lb.directory.Invalidate()
+
+	licenseGrant := grant.NewGrant(entitlement.Resource, licenseAssignment, principal.Id)
+
+	return []*v2.Grant{licenseGrant}, annotation, nil
+}
+
+// Revoke is not supported, every PandaDoc user holds a license. Grant another license to change it.
+func (lb *licenseBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	user, err := lb.directory.FindUser(ctx, grant.Principal.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.License != grant.Entitlement.Resource.Id.Resource {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	return nil, fmt.Errorf("baton-panda-doc: cannot revoke the %s license, grant another license to user %s instead", user.License, user.ID)
+}
+
+func newLicenseBuilder(client *client.PandaDocClient, directory *directory) *licenseBuilder {
+	return &licenseBuilder{
+		resourceType: licenseResourceType,
+		client:       client,
+		directory:    directory,
+	}
+}
+
+// assignedSeats counts the users holding the license.
+func assignedSeats(users []client.User, license string) int {
+	seats := 0
+	for _, user := range users {
+		if user.License == license {
+			seats++
+		}
+	}
+
+	return seats
+}
+
+// userLicenses returns the known licenses and any other license held by a user.
+func userLicenses(users []client.User) []string {
+	licenses := slices.Clone(knownLicenses)
+	for _, user := range users {
+		if user.License != "" && !slices.Contains(licenses, user.License) {
+			licenses = append(licenses, user.License)
+		}
+	}
+
+	return licenses
+}
@@ -0,0 +1,11 @@
+package connector
+
+// Licenses PandaDoc assigns to users. Other licenses are discovered from the users list.
+var knownLicenses = []string{
+	"Business",
+	"Enterprise",
+	"Full",
+	"Content Creator",
+	"Read-only",
+	"Guest",
+}
@@ -0,0 +1,151 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestLicenseBuilder_List(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	builder := newLicenseBuilder(testClient, newDirectory(testClient))
+
+	licenses, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The users only hold known licenses, nothing else is discovered.
+	if len(licenses) != len(knownLicenses) {
+		t.Errorf("Expected Count to be %d, got %d", len(knownLicenses), len(licenses))
+	}
+
+	users, err := builder.directory.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if seats := assignedSeats(users, "Full"); seats != 1 {
+		t.Errorf("Unexpected assigned seats: got %d, want %d", seats, 1)
+	}
+
+	guestLicense, err := parseIntoLicenseResource("Guest", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	grants, _, _, err := builder.Grants(ctx, guestLicense, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(grants) != 1 || grants[0].Principal.Id.Resource != "testUser01" {
+		t.Errorf("Expected a single grant for testUser01, got %v", grants)
+	}
+}
+
+func TestLicenseBuilder_Grant(t *testing.T) {
+	tests := []struct {
+		name     string
+		license  string
+		expected []string
+		exists   bool
+		err      string
+	}{
+		{
+			name:     "change license",
+			license:  "Full",
+			expected: []string{`PATCH /users/testUser01 {"license":"Full"}`},
+		},
+		{
+			name:    "already holds license",
+			license: "Guest",
+			exists:  true,
+		},
+		// Licenses that are neither known nor held by a user are rejected before any request.
+		{
+			name:    "unknown license",
+			license: "Platinum",
+			err:     "unknown license Platinum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			testClient := test.NewMockTestClient(writesRoundTrip(&writes))
+
+			ctx := context.Background()
+
+			builder := newLicenseBuilder(testClient, newDirectory(testClient))
+
+			licenseResource, err := parseIntoLicenseResource(tt.license, 0)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "testUser01"}}
+			grants, annos, err := builder.Grant(ctx, principal, &v2.Entitlement{Resource: licenseResource})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if fmt.Sprint(writes) != fmt.Sprint(tt.expected) {
+				t.Errorf("Unexpected requests: got %v, want %v", writes, tt.expected)
+			}
+
+			if tt.exists && !annos.Contains(&v2.GrantAlreadyExists{}) {
+				t.Errorf("Expected the grant to already exist, got %v", grants)
+			}
+		})
+	}
+}
+
+func TestLicenseBuilder_Revoke(t *testing.T) {
+	var writes []string
+	testClient := test.NewMockTestClient(writesRoundTrip(&writes))
+
+	ctx := context.Background()
+
+	builder := newLicenseBuilder(testClient, newDirectory(testClient))
+
+	guestLicense, err := parseIntoLicenseResource("Guest", 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Every user holds a license, it can only be changed by granting another one.
+	_, err = builder.Revoke(ctx, &v2.Grant{
+		Entitlement: &v2.Entitlement{Resource: guestLicense},
+		Principal:   &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "testUser01"}},
+	})
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+
+	if len(writes) != 0 {
+		t.Errorf("Expected no requests, got %v", writes)
+	}
+}
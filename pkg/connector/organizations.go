@@ -0,0 +1,92 @@
+package connector
+
+import (
+	"context"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+// The API key is scoped to a single organization and there is no endpoint for it, so it gets a fixed ID.
+const (
+	organizationID   = "organization"
+	organizationName = "PandaDoc Organization"
+	ownerEntitlement = "owner"
+)
+
+type organizationBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	directory    *directory
+}
+
+func (ob *organizationBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return ob.resourceType
+}
+
+func (ob *organizationBuilder) List(_ context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	organizationResource, err := parseIntoOrganizationResource()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return []*v2.Resource{organizationResource}, "", nil, nil
+}
+
+// This function builds the Organization Resource, workspaces are listed as its children.
+func parseIntoOrganizationResource() (*v2.Resource, error) {
+	ret, err := resource.NewResource(
+		organizationName,
+		organizationResourceType,
+		organizationID,
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: workspaceResourceType.Id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (ob *organizationBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	assigmentOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDescription("Owner of the PandaDoc organization"),
+		entitlement.WithDisplayName(ownerEntitlement),
+	}
+
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(resource, ownerEntitlement, assigmentOptions...),
+	}, "", nil, nil
+}
+
+func (ob *organizationBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var grants []*v2.Grant
+
+	users, err := ob.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, user := range users {
+		if user.IsOrganizationOwner {
+			userResource, _ := parseIntoUserResource(ctx, &user, nil)
+			grants = append(grants, grant.NewGrant(resource, ownerEntitlement, userResource))
+		}
+	}
+
+	return grants, "", nil, nil
+}
+
+func newOrganizationBuilder(client *client.PandaDocClient, directory *directory) *organizationBuilder {
+	return &organizationBuilder{
+		resourceType: organizationResourceType,
+		client:       client,
+		directory:    directory,
+	}
+}
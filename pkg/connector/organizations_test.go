@@ -0,0 +1,47 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestOrganizationBuilder_Grants(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	builder := newOrganizationBuilder(testClient, newDirectory(testClient))
+
+	organizations, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(organizations) != 1 {
+		t.Fatalf("Expected Count to be 1, got %d", len(organizations))
+	}
+
+	grants, _, _, err := builder.Grants(ctx, organizations[0], &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Only testUser02 is the organization owner.
+	if len(grants) != 1 || grants[0].Principal.Id.Resource != "testUser02" {
+		t.Errorf("Expected a single grant for testUser02, got %v", grants)
+	}
+}
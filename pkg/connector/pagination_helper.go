@@ -3,6 +3,7 @@ package connector
 import (
 	"strconv"
 
+	"github.com/conductorone/baton-panda-doc/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 )
@@ -50,3 +51,19 @@ func unmarshalSkipToken(token *pagination.Token) (int32, *pagination.Bag, error)
 
 	return ret, b, nil
 }
+
+// pageBounds returns the bounds of the page of size items starting at offset, out of total items,
+// and the token of the next page, which is empty on the last page.
+func pageBounds(offset, size, total int) (int, int, string) {
+	if size <= 0 {
+		size = client.ItemsPerPage
+	}
+
+	start := min(offset, total)
+	end := min(start+size, total)
+	if end == total {
+		return start, end, ""
+	}
+
+	return start, end, strconv.Itoa(end)
+}
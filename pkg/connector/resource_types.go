@@ -24,3 +24,61 @@ var roleResourceType = &v2.ResourceType{
 	Description: "A role is a set of permissions that can be assigned to a user.",
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
 }
+
+var licenseResourceType = &v2.ResourceType{
+	Id:          "license",
+	DisplayName: "License",
+	Description: "A license is the seat type of a user in the PandaDoc organization.",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+}
+
+var organizationResourceType = &v2.ResourceType{
+	Id:          "organization",
+	DisplayName: "Organization",
+	Description: "The PandaDoc organization, it contains every workspace.",
+}
+
+var contactResourceType = &v2.ResourceType{
+	Id:          "contact",
+	DisplayName: "Contact",
+	Description: "A contact is an external recipient documents are sent to.",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+}
+
+var documentResourceType = &v2.ResourceType{
+	Id:          "document",
+	DisplayName: "Document",
+	Description: "A document is owned by the user that created it and sent to its recipients.",
+}
+
+var templateResourceType = &v2.ResourceType{
+	Id:          "template",
+	DisplayName: "Template",
+	Description: "A template documents are created from, it can be shared with users and workspaces.",
+}
+
+var documentFolderResourceType = &v2.ResourceType{
+	Id:          "document_folder",
+	DisplayName: "Document Folder",
+	Description: "A folder of documents, folders are nested under their workspace or parent folder.",
+}
+
+var templateFolderResourceType = &v2.ResourceType{
+	Id:          "template_folder",
+	DisplayName: "Template Folder",
+	Description: "A folder of templates, folders are nested under their workspace or parent folder.",
+}
+
+var webhookSubscriptionResourceType = &v2.ResourceType{
+	Id:          "webhook_subscription",
+	DisplayName: "Webhook Subscription",
+	Description: "A webhook subscription sends document data to its URL, signed with its shared key.",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
+
+var apiKeyResourceType = &v2.ResourceType{
+	Id:          "api_key",
+	DisplayName: "API Key",
+	Description: "A PandaDoc API key, issued to a user for a workspace.",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
@@ -3,8 +3,7 @@ package connector
 import (
 	"context"
 	"fmt"
-	"slices"
-	"sync"
+	"strings"
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -13,15 +12,17 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
+const roleAssignment = "assigned"
+
 type roleBuilder struct {
-	resourceType    *v2.ResourceType
-	client          *client.PandaDocClient
-	users           []client.User
-	usersMutex      sync.RWMutex
-	workspaces      []client.Workspace
-	workspacesMutex sync.RWMutex
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	defaultRole  string
+	directory    *directory
 }
 
 func (rb *roleBuilder) ResourceType(_ context.Context) *v2.ResourceType {
@@ -30,228 +31,250 @@ func (rb *roleBuilder) ResourceType(_ context.Context) *v2.ResourceType {
 
 // There is no endpoint for Roles.
 // There are 4 system roles and custom roles can be created. We'll retrieve custom roles names from the users list.
+// Roles are listed under each workspace, a custom role is only listed in the workspaces where it is used.
 func (rb *roleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
 	var rolesResource []*v2.Resource
+	if parentResourceID == nil || parentResourceID.ResourceType != workspaceResourceType.Id {
+		return nil, "", nil, nil
+	}
 
+	workspaceID := parentResourceID.Resource
 	for _, role := range systemRoles {
 		roleCopy := role
-		roleResource, err := parseIntoRoleResource(ctx, &roleCopy, nil)
+		roleResource, err := parseIntoRoleResource(ctx, &roleCopy, parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
 		rolesResource = append(rolesResource, roleResource)
 	}
 
-	err := rb.GetUsers(ctx)
+	users, err := rb.directory.Users(ctx)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	systemRoles := []string{"Admin", "Collaborator", "Member", "Manager"}
-
-	users := rb.users
+	listed := make(map[string]bool)
 	for _, user := range users {
 		for _, workspace := range user.Workspaces {
-			if !slices.Contains(systemRoles, workspace.Role) {
-				newRole := client.Role{
-					Name:        workspace.Role,
-					IsSystem:    false,
-					Description: "Custom role",
-				}
-				roleResource, err := parseIntoRoleResource(ctx, &newRole, nil)
-				if err != nil {
-					return nil, "", nil, err
-				}
-				rolesResource = append(rolesResource, roleResource)
+			if workspace.WorkspaceID != workspaceID || isSystemRole(workspace.Role) || listed[workspace.Role] {
+				continue
+			}
+			listed[workspace.Role] = true
+
+			newRole := client.Role{
+				Name:        workspace.Role,
+				IsSystem:    false,
+				Description: "Custom role",
+			}
+			roleResource, err := parseIntoRoleResource(ctx, &newRole, parentResourceID)
+			if err != nil {
+				return nil, "", nil, err
 			}
+			rolesResource = append(rolesResource, roleResource)
 		}
 	}
 
 	return rolesResource, "", nil, nil
 }
 
-func (rb *roleBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	var rv []*v2.Entitlement
-
-	err := rb.GetWorkspaces(ctx)
-
-	if err != nil {
-		return nil, "", nil, err
+func (rb *roleBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	assigmentOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDescription(fmt.Sprintf("%s role in the workspace", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, roleAssignment)),
 	}
 
-	workspaces := rb.workspaces
-
-	for _, workspace := range workspaces {
-		permissionName := fmt.Sprintf("assigned in workspace %s", workspace.Name)
-
-		assigmentOptions := []entitlement.EntitlementOption{
-			entitlement.WithGrantableTo(userResourceType),
-			entitlement.WithDescription(resource.Description),
-			entitlement.WithDisplayName(resource.DisplayName),
-		}
-		rv = append(rv, entitlement.NewPermissionEntitlement(resource, permissionName, assigmentOptions...))
-	}
-
-	return rv, "", nil, nil
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(resource, roleAssignment, assigmentOptions...),
+	}, "", nil, nil
 }
 
+// Grants returns a grant for every member of the workspace of the role that holds it.
+// The users are walked in pages, so the grants of a role held by thousands of members are split across pages.
 func (rb *roleBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	var grants []*v2.Grant
 
-	err := rb.GetUsers(ctx)
+	workspaceID, role, err := parseRoleID(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
 
+	bag, offset, err := getToken(pToken, userResourceType)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	users := rb.users
-	for _, user := range users {
+	users, err := rb.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	start, end, nextPage := pageBounds(offset, pToken.Size, len(users))
+	for _, user := range users[start:end] {
 		for _, workspace := range user.Workspaces {
-			if workspace.Role == resource.Id.Resource {
-				workspaceName, err := rb.GetWorkspaceName(ctx, workspace.WorkspaceID)
-				if err != nil {
-					return nil, "", nil, err
-				}
-				entitlementName := fmt.Sprintf("assigned in workspace %s", workspaceName)
+			if workspace.WorkspaceID == workspaceID && workspace.Role == role {
 				userResource, _ := parseIntoUserResource(ctx, &user, resource.Id)
-				membershipGrant := grant.NewGrant(resource, entitlementName, userResource, grant.WithAnnotation(&v2.V1Identifier{
-					Id: fmt.Sprintf("workspace-grant:%s:%s:%s", resource.Id.Resource, workspace.MembershipID, workspace.Role),
+				membershipGrant := grant.NewGrant(resource, roleAssignment, userResource, grant.WithAnnotation(&v2.V1Identifier{
+					Id: fmt.Sprintf("workspace-grant:%s:%s:%s", workspaceID, workspace.MembershipID, workspace.Role),
 				}))
 				grants = append(grants, membershipGrant)
 			}
 		}
 	}
 
-	return grants, "", nil, nil
-}
-
-func newRolesBuilder(client *client.PandaDocClient) *roleBuilder {
-	return &roleBuilder{
-		resourceType: roleResourceType,
-		client:       client,
-	}
-}
-
-func parseIntoRoleResource(_ context.Context, role *client.Role, _ *v2.ResourceId) (*v2.Resource, error) {
-	profile := map[string]interface{}{
-		"id":        role.Name,
-		"name":      role.Name,
-		"is_system": role.IsSystem,
-	}
-
-	roleTraits := []rs.RoleTraitOption{
-		rs.WithRoleProfile(profile),
+	err = bag.Next(nextPage)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	ret, err := rs.NewRoleResource(role.Name, roleResourceType, role.Name, roleTraits)
+	nextPageToken, err := bag.Marshal()
 	if err != nil {
-		return nil, err
+		return nil, "", nil, err
 	}
 
-	return ret, nil
+	return grants, nextPageToken, nil, nil
 }
 
-func (rb *roleBuilder) GetUsers(ctx context.Context) error {
-	rb.usersMutex.RLock()
-	defer rb.usersMutex.RUnlock()
+// Grant sets the role of the user in the workspace of the entitlement.
+// Users that are not members of the workspace yet are added to it with the role.
+func (rb *roleBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"baton-panda-doc: only users can be granted roles",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, nil, fmt.Errorf("baton-panda-doc: only users can be granted roles")
+	}
 
-	paginationToken := pagination.Token{
-		Size:  50,
-		Token: "",
+	userID := principal.Id.Resource
+	workspaceID, role, err := parseRoleID(entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if rb.users != nil {
-		return nil
+	membership, err := rb.directory.Membership(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	for {
-		bag, pageToken, err := getToken(&paginationToken, userResourceType)
-		if err != nil {
-			return err
-		}
-		users, nextPageToken, _, err := rb.client.ListUsers(ctx, client.PageOptions{
-			Count: paginationToken.Size,
-			Page:  pageToken,
+	var annotation annotations.Annotations
+	switch {
+	case membership == nil:
+		annotation, err = rb.client.AddWorkspaceMember(ctx, workspaceID, client.WorkspaceMemberRequest{
+			UserID: userID,
+			Role:   role,
 		})
 		if err != nil {
-			return err
+			return nil, nil, fmt.Errorf("baton-panda-doc: failed to add user %s to workspace %s as %s: %w", userID, workspaceID, role, err)
 		}
-		err = bag.Next(nextPageToken)
+	case membership.Role == role:
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+	default:
+		annotation, err = rb.client.UpdateWorkspaceMemberRole(ctx, workspaceID, membership.MembershipID, role)
 		if err != nil {
-			return err
+			return nil, nil, fmt.Errorf("baton-panda-doc: failed to change role of user %s in workspace %s to %s: %w", userID, workspaceID, role, err)
 		}
-
-		rb.users = append(rb.users, users...)
-		nextPageToken, err = bag.Marshal()
-		if err != nil {
-			return err
-		}
-		if nextPageToken == "" {
-			break
-		}
-		paginationToken.Token = nextPageToken
 	}
+	rb.directory.Invalidate()
+
+	roleGrant := grant.NewGrant(entitlement.Resource, roleAssignment, principal.Id)
 
-	return nil
+	return []*v2.Grant{roleGrant}, annotation, nil
 }
 
-func (rb *roleBuilder) GetWorkspaces(ctx context.Context) error {
-	rb.workspacesMutex.RLock()
-	defer rb.workspacesMutex.RUnlock()
+// Revoke sets the role of the user in the workspace back to the default role.
+// The user keeps its workspace membership, use the workspace member entitlement to remove it.
+func (rb *roleBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := grant.Principal
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"baton-panda-doc: only users can have roles revoked",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("baton-panda-doc: only users can have roles revoked")
+	}
 
-	paginationToken := pagination.Token{
-		Size:  50,
-		Token: "",
+	userID := principal.Id.Resource
+	workspaceID, role, err := parseRoleID(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
 	}
 
-	if rb.workspaces != nil {
-		return nil
+	if role == rb.defaultRole {
+		return nil, fmt.Errorf("baton-panda-doc: cannot revoke the default role %s, revoke the workspace membership instead", role)
 	}
 
-	for {
-		bag, pageToken, err := getToken(&paginationToken, workspaceResourceType)
-		if err != nil {
-			return err
-		}
-		workspaces, nextPageToken, _, err := rb.client.ListWorkspaces(ctx, client.PageOptions{
-			Count: paginationToken.Size,
-			Page:  pageToken,
-		})
-		if err != nil {
-			return err
-		}
-		err = bag.Next(nextPageToken)
-		if err != nil {
-			return err
-		}
+	membership, err := rb.directory.Membership(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
 
-		rb.workspaces = append(rb.workspaces, workspaces...)
-		nextPageToken, err = bag.Marshal()
-		if err != nil {
-			return err
-		}
-		if nextPageToken == "" {
-			break
-		}
-		paginationToken.Token = nextPageToken
+	if membership == nil || membership.Role != role {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
 	}
 
-	return nil
+	annotation, err := rb.client.UpdateWorkspaceMemberRole(ctx, workspaceID, membership.MembershipID, rb.defaultRole)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: failed to change role of user %s in workspace %s to %s: %w", userID, workspaceID, rb.defaultRole, err)
+	}
+	rb.directory.Invalidate()
+
+	return annotation, nil
 }
 
-func (rb *roleBuilder) GetWorkspaceName(ctx context.Context, workspaceID string) (string, error) {
-	err := rb.GetWorkspaces(ctx)
+func newRolesBuilder(client *client.PandaDocClient, directory *directory, defaultRole string) *roleBuilder {
+	return &roleBuilder{
+		resourceType: roleResourceType,
+		client:       client,
+		directory:    directory,
+		defaultRole:  defaultRole,
+	}
+}
 
-	if err != nil {
-		return "", err
+// roleID returns the ID of the role resource, roles are scoped to a workspace.
+func roleID(workspaceID, role string) string {
+	return workspaceID + ":" + role
+}
+
+// parseRoleID splits the ID of a role resource into its workspace ID and role name.
+// Role names can contain colons, workspace IDs can't.
+func parseRoleID(id string) (string, string, error) {
+	workspaceID, role, found := strings.Cut(id, ":")
+	if !found || workspaceID == "" || role == "" {
+		return "", "", fmt.Errorf("baton-panda-doc: invalid role %s", id)
+	}
+
+	return workspaceID, role, nil
+}
+
+func parseIntoRoleResource(_ context.Context, role *client.Role, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":           role.Name,
+		"name":         role.Name,
+		"is_system":    role.IsSystem,
+		"workspace_id": parentResourceID.Resource,
 	}
-	workspaceMap := make(map[string]string)
-	for _, w := range rb.workspaces {
-		workspaceMap[w.ID] = w.Name
+
+	roleTraits := []rs.RoleTraitOption{
+		rs.WithRoleProfile(profile),
 	}
-	if name, exists := workspaceMap[workspaceID]; exists {
-		return name, nil
-	} else {
-		return "", fmt.Errorf("provided workspace id %s does not exists", workspaceID)
+
+	ret, err := rs.NewRoleResource(
+		role.Name,
+		roleResourceType,
+		roleID(parentResourceID.Resource, role.Name),
+		roleTraits,
+		rs.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, err
 	}
+
+	return ret, nil
 }
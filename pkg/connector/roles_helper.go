@@ -1,6 +1,8 @@
 package connector
 
 import (
+	"slices"
+
 	"github.com/conductorone/baton-panda-doc/pkg/client"
 )
 
@@ -22,3 +24,9 @@ var systemRoles = []client.Role{
 		IsSystem: true,
 	},
 }
+
+func isSystemRole(name string) bool {
+	return slices.ContainsFunc(systemRoles, func(role client.Role) bool {
+		return role.Name == name
+	})
+}
@@ -0,0 +1,336 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestParseRoleID(t *testing.T) {
+	workspaceID, role, err := parseRoleID(roleID("testWorkspace01", "Sales: Ops"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if workspaceID != "testWorkspace01" || role != "Sales: Ops" {
+		t.Errorf("Unexpected role: got %s in %s, want %s in %s", role, workspaceID, "Sales: Ops", "testWorkspace01")
+	}
+
+	// Role IDs without a workspace must be rejected.
+	_, _, err = parseRoleID("Custom Role")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestRoleBuilder_ListPerWorkspace(t *testing.T) {
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newRolesBuilder(testClient, newDirectory(testClient), "Member")
+
+	tests := []struct {
+		workspaceID string
+		roles       int
+		adminGrants []string
+	}{
+		{workspaceID: "TestWorkspace01", roles: len(systemRoles), adminGrants: []string{"role:TestWorkspace01:Admin:assigned:user:testUser02"}},
+		{workspaceID: "TestWorkspace02", roles: len(systemRoles) + 1, adminGrants: []string{"role:TestWorkspace02:Admin:assigned:user:testUser02"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.workspaceID, func(t *testing.T) {
+			workspaceID := &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: tt.workspaceID}
+
+			roles, _, _, err := builder.List(ctx, workspaceID, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(roles) != tt.roles {
+				t.Fatalf("Expected Count to be %d, got %d", tt.roles, len(roles))
+			}
+
+			for _, role := range roles {
+				if !strings.HasPrefix(role.Id.Resource, tt.workspaceID+":") {
+					t.Errorf("Expected role %s to be scoped to %s", role.Id.Resource, tt.workspaceID)
+				}
+
+				entitlements, _, _, err := builder.Entitlements(ctx, role, &pagination.Token{})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(entitlements) != 1 {
+					t.Errorf("Expected one entitlement for %s, got %d", role.Id.Resource, len(entitlements))
+				}
+
+				if role.DisplayName != "Admin" {
+					continue
+				}
+
+				grants, _, _, err := builder.Grants(ctx, role, &pagination.Token{})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(grants) != len(tt.adminGrants) {
+					t.Fatalf("Expected %d grants, got %d", len(tt.adminGrants), len(grants))
+				}
+				for index, roleGrant := range grants {
+					if roleGrant.Id != tt.adminGrants[index] {
+						t.Errorf("Unexpected grant: got %s, want %s", roleGrant.Id, tt.adminGrants[index])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoleBuilder_GrantsPages(t *testing.T) {
+	users := make([]any, 0, 120)
+	for i := range 120 {
+		users = append(users, client.User{
+			ID:    fmt.Sprintf("testUser%03d", i),
+			Email: fmt.Sprintf("testUser%03d@test.com", i),
+			Workspaces: []client.Membership{
+				{
+					Role:         "Member",
+					WorkspaceID:  "testWorkspace01",
+					MembershipID: fmt.Sprintf("testMember%03d", i),
+				},
+			},
+		})
+	}
+
+	requests := 0
+	testClient := test.NewMockTestClient(test.PagedRoundTrip(users, &requests))
+
+	ctx := context.Background()
+
+	builder := newRolesBuilder(testClient, newDirectory(testClient), "Member")
+
+	roleResource, err := parseIntoRoleResource(ctx, &client.Role{Name: "Member", IsSystem: true}, &v2.ResourceId{
+		ResourceType: workspaceResourceType.Id,
+		Resource:     "testWorkspace01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var pages []int
+	pToken := &pagination.Token{Size: 50}
+	for {
+		grants, nextPageToken, _, err := builder.Grants(ctx, roleResource, pToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		pages = append(pages, len(grants))
+
+		if nextPageToken == "" {
+			break
+		}
+		pToken.Token = nextPageToken
+	}
+
+	if fmt.Sprint(pages) != "[50 50 20]" {
+		t.Errorf("Unexpected pages: got %v, want [50 50 20]", pages)
+	}
+
+	// The pages are read from the users snapshot, listed once for every role.
+	adminResource, err := parseIntoRoleResource(ctx, &client.Role{Name: "Admin", IsSystem: true}, roleResource.ParentResourceId)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, _, _, err = builder.Grants(ctx, adminResource, &pagination.Token{Size: 50})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+// writesRoundTrip serves the mock users and records every other request as its method, path and body.
+func writesRoundTrip(writes *[]string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+
+		if req.Method != http.MethodGet {
+			var body []byte
+			if req.Body != nil {
+				body, _ = io.ReadAll(req.Body)
+			}
+			// Requests without a body are sent with a JSON null.
+			write := fmt.Sprintf("%s %s", req.Method, req.URL.Path)
+			if payload := strings.TrimSpace(string(body)); payload != "null" && payload != "" {
+				write += " " + payload
+			}
+			*writes = append(*writes, write)
+			resp.Body = io.NopCloser(strings.NewReader("{}"))
+		}
+
+		return resp, nil
+	}
+}
+
+func roleEntitlement(t *testing.T, workspaceID, role string) *v2.Entitlement {
+	roleResource, err := parseIntoRoleResource(context.Background(), &client.Role{Name: role, IsSystem: true}, &v2.ResourceId{
+		ResourceType: workspaceResourceType.Id,
+		Resource:     workspaceID,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return &v2.Entitlement{Resource: roleResource}
+}
+
+func TestRoleBuilder_Grant(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    string
+		workspace string
+		role      string
+		expected  []string
+		exists    bool
+	}{
+		// testUser01 is not a member of TestWorkspace03, it is added to it with the role.
+		{
+			name:      "add member",
+			userID:    "testUser01",
+			workspace: "TestWorkspace03",
+			role:      "Manager",
+			expected:  []string{`POST /workspaces/TestWorkspace03/members {"user_id":"testUser01","role":"Manager"}`},
+		},
+		// testUser01 is a Collaborator of TestWorkspace01, its membership gets the role.
+		{
+			name:      "change role",
+			userID:    "testUser01",
+			workspace: "TestWorkspace01",
+			role:      "Manager",
+			expected:  []string{`PATCH /workspaces/TestWorkspace01/members/testMember01 {"role":"Manager"}`},
+		},
+		{
+			name:      "already holds role",
+			userID:    "testUser02",
+			workspace: "TestWorkspace01",
+			role:      "Admin",
+			exists:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			testClient := test.NewMockTestClient(writesRoundTrip(&writes))
+
+			ctx := context.Background()
+
+			builder := newRolesBuilder(testClient, newDirectory(testClient), "Member")
+
+			principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: tt.userID}}
+			grants, annos, err := builder.Grant(ctx, principal, roleEntitlement(t, tt.workspace, tt.role))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if fmt.Sprint(writes) != fmt.Sprint(tt.expected) {
+				t.Errorf("Unexpected requests: got %v, want %v", writes, tt.expected)
+			}
+
+			if tt.exists {
+				if len(grants) != 0 || !annos.Contains(&v2.GrantAlreadyExists{}) {
+					t.Errorf("Expected the grant to already exist, got %v", grants)
+				}
+				return
+			}
+
+			if len(grants) != 1 || grants[0].Principal.Id.Resource != tt.userID {
+				t.Errorf("Expected a grant for %s, got %v", tt.userID, grants)
+			}
+		})
+	}
+}
+
+func TestRoleBuilder_Revoke(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		role     string
+		expected []string
+		revoked  bool
+		err      string
+	}{
+		// The user is set back to the default role, it stays a member of the workspace.
+		{
+			name:     "reset to default role",
+			userID:   "testUser02",
+			role:     "Admin",
+			expected: []string{`PATCH /workspaces/TestWorkspace01/members/testMember02 {"role":"Member"}`},
+		},
+		{
+			name:    "role not held",
+			userID:  "testUser01",
+			role:    "Admin",
+			revoked: true,
+		},
+		{
+			name:   "default role",
+			userID: "testUser01",
+			role:   "Member",
+			err:    "cannot revoke the default role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			testClient := test.NewMockTestClient(writesRoundTrip(&writes))
+
+			ctx := context.Background()
+
+			builder := newRolesBuilder(testClient, newDirectory(testClient), "Member")
+
+			annos, err := builder.Revoke(ctx, &v2.Grant{
+				Entitlement: roleEntitlement(t, "TestWorkspace01", tt.role),
+				Principal:   &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: tt.userID}},
+			})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if fmt.Sprint(writes) != fmt.Sprint(tt.expected) {
+				t.Errorf("Unexpected requests: got %v, want %v", writes, tt.expected)
+			}
+
+			if tt.revoked && !annos.Contains(&v2.GrantAlreadyRevoked{}) {
+				t.Errorf("Expected the grant to already be revoked")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package connector
+
+import (
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+)
+
+const (
+	shareEditor = "editor"
+	shareViewer = "viewer"
+
+	// Shares with this permission allow to edit, any other permission only allows to view and use.
+	shareEditPermission = "edit"
+)
+
+// shareGrants returns an editor or viewer grant for every user and workspace in shares.
+// Users are matched by email when possible, workspace grants expand to the members of the workspace.
+func shareGrants(resource *v2.Resource, shares []client.Share, users []client.User) []*v2.Grant {
+	var grants []*v2.Grant
+
+	for _, share := range shares {
+		entitlementName := shareViewer
+		if share.Permission == shareEditPermission {
+			entitlementName = shareEditor
+		}
+
+		switch share.Type {
+		case "user":
+			userID := share.ID
+			if user := findUserByEmail(users, share.Email); user != nil {
+				userID = user.ID
+			}
+			grants = append(grants, grant.NewGrant(resource, entitlementName, &v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     userID,
+			}))
+		case "workspace":
+			workspaceID := &v2.ResourceId{
+				ResourceType: workspaceResourceType.Id,
+				Resource:     share.ID,
+			}
+			memberEntitlementID := entitlement.NewEntitlementID(&v2.Resource{Id: workspaceID}, permissionName)
+			grants = append(grants, grant.NewGrant(resource, entitlementName, workspaceID, grant.WithAnnotation(&v2.GrantExpandable{
+				EntitlementIds: []string{memberEntitlementID},
+			})))
+		}
+	}
+
+	return grants
+}
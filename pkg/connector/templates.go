@@ -0,0 +1,150 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const templateOwner = "owner"
+
+type templateBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+	directory    *directory
+}
+
+func (tb *templateBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return tb.resourceType
+}
+
+func (tb *templateBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var resources []*v2.Resource
+
+	bag, pageToken, err := getToken(pToken, templateResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	templates, nextPage, annotation, err := tb.client.ListTemplates(ctx, client.PageOptions{
+		Page:  pageToken,
+		Count: pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	err = bag.Next(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, template := range templates {
+		templateResource, err := parseIntoTemplateResource(&template)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		resources = append(resources, templateResource)
+	}
+
+	return resources, nextPageToken, annotation, nil
+}
+
+// This function parses a template from PandaDoc into a Template Resource.
+func parseIntoTemplateResource(template *client.Template) (*v2.Resource, error) {
+	description := fmt.Sprintf(
+		"Created %s, modified %s",
+		template.DateCreated.Format(time.RFC3339),
+		template.DateModified.Format(time.RFC3339),
+	)
+
+	ret, err := resource.NewResource(
+		template.Name,
+		templateResourceType,
+		template.ID,
+		resource.WithDescription(description),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (tb *templateBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	ownerOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Owner of the %s template", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, templateOwner)),
+	}
+
+	editorOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType, workspaceResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Can edit the %s template", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, shareEditor)),
+	}
+
+	viewerOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType, workspaceResourceType),
+		entitlement.WithDescription(fmt.Sprintf("Can use the %s template", resource.DisplayName)),
+		entitlement.WithDisplayName(fmt.Sprintf("%s %s", resource.DisplayName, shareViewer)),
+	}
+
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(resource, templateOwner, ownerOptions...),
+		entitlement.NewPermissionEntitlement(resource, shareEditor, editorOptions...),
+		entitlement.NewPermissionEntitlement(resource, shareViewer, viewerOptions...),
+	}, "", nil, nil
+}
+
+// Grants returns the owner grant and a grant for every user and workspace the template is shared with.
+// Workspace grants expand to the members of the workspace.
+func (tb *templateBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var grants []*v2.Grant
+
+	details, annotation, err := tb.client.GetTemplateDetails(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	users, err := tb.directory.Users(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	ownerID := details.CreatedBy.ID
+	if owner := findUserByEmail(users, details.CreatedBy.Email); owner != nil {
+		ownerID = owner.ID
+	}
+	if ownerID != "" {
+		grants = append(grants, grant.NewGrant(resource, templateOwner, &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     ownerID,
+		}))
+	}
+
+	grants = append(grants, shareGrants(resource, details.SharedWith, users)...)
+
+	return grants, "", annotation, nil
+}
+
+func newTemplateBuilder(c *client.PandaDocClient, directory *directory) *templateBuilder {
+	return &templateBuilder{
+		resourceType: templateResourceType,
+		client:       c,
+		directory:    directory,
+	}
+}
@@ -0,0 +1,78 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestTemplateBuilder_Grants(t *testing.T) {
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		var mockFile string
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/details"):
+			mockFile = "mock_template_details.json"
+		case strings.HasSuffix(req.URL.Path, "/templates"):
+			mockFile = "mock_templates.json"
+		default:
+			mockFile = "mock_users.json"
+		}
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile(mockFile))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newTemplateBuilder(testClient, newDirectory(testClient))
+
+	templates, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(templates) != 1 {
+		t.Fatalf("Expected Count to be 1, got %d", len(templates))
+	}
+
+	grants, _, _, err := builder.Grants(ctx, templates[0], &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"template:testTemplate01:owner:user:testUser01",
+		"template:testTemplate01:editor:user:testUser02",
+		"template:testTemplate01:viewer:workspace:testWorkspace01",
+	}
+	if len(grants) != len(expected) {
+		t.Fatalf("Expected %d grants, got %d", len(expected), len(grants))
+	}
+	for index, templateGrant := range grants {
+		if templateGrant.Id != expected[index] {
+			t.Errorf("Unexpected grant: got %s, want %s", templateGrant.Id, expected[index])
+		}
+	}
+
+	expandable := &v2.GrantExpandable{}
+	grantAnnotations := annotations.Annotations(grants[2].Annotations)
+	ok, err := grantAnnotations.Pick(expandable)
+	if err != nil || !ok {
+		t.Fatalf("Expected the workspace grant to be expandable, got %v", err)
+	}
+	if len(expandable.EntitlementIds) != 1 || expandable.EntitlementIds[0] != "workspace:testWorkspace01:member" {
+		t.Errorf("Unexpected expandable entitlements: %v", expandable.EntitlementIds)
+	}
+}
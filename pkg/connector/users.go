@@ -2,11 +2,14 @@ package connector
 
 import (
 	"context"
-	"sync"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 )
@@ -14,8 +17,8 @@ import (
 type userBuilder struct {
 	resourceType *v2.ResourceType
 	client       *client.PandaDocClient
-	users        []client.User
-	usersMutex   sync.RWMutex
+	directory    *directory
+	defaultRole  string
 }
 
 func (ub *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -27,13 +30,30 @@ func (ub *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 func (ub *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
 	var resources []*v2.Resource
 
-	nextPageToken, annotation, err := ub.GetUsers(ctx, pToken)
+	bag, pageToken, err := getToken(pToken, userResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	users, nextPage, annotation, err := ub.client.ListUsers(ctx, client.PageOptions{
+		Count: pToken.Size,
+		Page:  pageToken,
+	})
+	if err != nil {
+		return nil, "", nil, err
+	}
 
+	err = bag.Next(nextPage)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	for _, user := range ub.users {
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, user := range users {
 		userCopy := user
 		userResource, err := parseIntoUserResource(ctx, &userCopy, nil)
 		if err != nil {
@@ -90,43 +110,173 @@ func (ub *userBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return nil, "", nil, nil
 }
 
-func newUserBuilder(c *client.PandaDocClient) *userBuilder {
-	return &userBuilder{
-		resourceType: userResourceType,
-		client:       c,
+// CreateAccount creates a PandaDoc user. PandaDoc users must belong to at least one workspace,
+// so the profile has to carry the workspace_id the user is added to.
+func (ub *userBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	_ *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	user, err := createUserRequest(accountInfo, ub.defaultRole)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+
+	createdUser, annotation, err := ub.client.CreateUser(ctx, *user)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("baton-panda-doc: failed to create user %s: %w", user.User.Email, err)
+	}
+	ub.directory.Invalidate()
+
+	userResource, err := parseIntoUserResource(ctx, createdUser, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource: userResource,
+	}, nil, annotation, nil
+}
+
+// CreateAccountCapabilityDetails advertises that PandaDoc users are created without a password,
+// they set it themselves from the invitation email.
+func (ub *userBuilder) CreateAccountCapabilityDetails(_ context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return &v2.CredentialDetailsAccountProvisioning{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+	}, nil, nil
 }
 
-func (ub *userBuilder) GetUsers(ctx context.Context, pToken *pagination.Token) (string, annotations.Annotations, error) {
-	ub.usersMutex.RLock()
-	defer ub.usersMutex.RUnlock()
+// MembershipRemovalError is returned by Delete when the user could not be removed from some of its workspaces.
+type MembershipRemovalError struct {
+	UserID string
+	// Failures maps the ID of each membership that could not be removed to the reason.
+	Failures map[string]error
+}
 
-	if ub.users != nil {
-		return "", nil, nil
+func (e *MembershipRemovalError) Error() string {
+	membershipIDs := make([]string, 0, len(e.Failures))
+	for membershipID := range e.Failures {
+		membershipIDs = append(membershipIDs, membershipID)
 	}
+	sort.Strings(membershipIDs)
 
-	bag, pageToken, err := getToken(pToken, userResourceType)
-	if err != nil {
-		return "", nil, err
+	failures := make([]string, 0, len(membershipIDs))
+	for _, membershipID := range membershipIDs {
+		failures = append(failures, fmt.Sprintf("%s: %s", membershipID, e.Failures[membershipID]))
 	}
 
-	users, nextPageToken, _, err := ub.client.ListUsers(ctx, client.PageOptions{
-		Count: pToken.Size,
-		Page:  pageToken,
-	})
-	if err != nil {
-		return "", nil, err
+	return fmt.Sprintf("baton-panda-doc: failed to remove user %s from memberships %s", e.UserID, strings.Join(failures, "; "))
+}
+
+func (e *MembershipRemovalError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, err := range e.Failures {
+		errs = append(errs, err)
 	}
-	err = bag.Next(nextPageToken)
-	if err != nil {
-		return "", nil, err
+	return errs
+}
+
+// Delete removes the user from every workspace it is a member of, which removes it from the organization.
+// The organization owner is never deleted.
+func (ub *userBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("baton-panda-doc: only users can be deleted by the user builder")
 	}
 
-	ub.users = users
-	nextPageToken, err = bag.Marshal()
+	user, err := ub.directory.FindUser(ctx, resourceId.Resource)
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("baton-panda-doc: user %s not found", resourceId.Resource)
+	}
+
+	if user.IsOrganizationOwner {
+		return nil, fmt.Errorf("baton-panda-doc: refusing to delete user %s, it is the organization owner", user.Email)
+	}
+
+	var rv annotations.Annotations
+	removalErr := &MembershipRemovalError{
+		UserID:   user.ID,
+		Failures: make(map[string]error),
+	}
+	for _, workspace := range user.Workspaces {
+		annotation, err := ub.client.RemoveWorkspaceMember(ctx, workspace.WorkspaceID, workspace.MembershipID)
+		if err != nil {
+			removalErr.Failures[workspace.MembershipID] = fmt.Errorf("workspace %s: %w", workspace.WorkspaceID, err)
+			continue
+		}
+		rv = append(rv, annotation...)
+	}
+	ub.directory.Invalidate()
+
+	if len(removalErr.Failures) > 0 {
+		return rv, removalErr
+	}
+
+	return rv, nil
+}
+
+func newUserBuilder(c *client.PandaDocClient, directory *directory, defaultRole string) *userBuilder {
+	return &userBuilder{
+		resourceType: userResourceType,
+		client:       c,
+		directory:    directory,
+		defaultRole:  defaultRole,
+	}
+}
+
+// createUserRequest builds the PandaDoc user creation request from the account info.
+func createUserRequest(accountInfo *v2.AccountInfo, defaultRole string) (*client.CreateUserRequest, error) {
+	profile := accountInfo.GetProfile()
+
+	email := accountInfo.GetLogin()
+	for _, e := range accountInfo.GetEmails() {
+		if email == "" || e.GetIsPrimary() {
+			email = e.GetAddress()
+		}
+	}
+	if email == "" {
+		if profileEmail, ok := resource.GetProfileStringValue(profile, "email"); ok {
+			email = profileEmail
+		}
 	}
+	if email == "" {
+		return nil, fmt.Errorf("baton-panda-doc: email is required to create a user")
+	}
+
+	workspaceID, ok := resource.GetProfileStringValue(profile, "workspace_id")
+	if !ok || workspaceID == "" {
+		return nil, fmt.Errorf("baton-panda-doc: workspace_id is required to create a user")
+	}
+
+	role, ok := resource.GetProfileStringValue(profile, "role")
+	if !ok || role == "" {
+		role = defaultRole
+	}
+
+	firstName, _ := resource.GetProfileStringValue(profile, "first_name")
+	lastName, _ := resource.GetProfileStringValue(profile, "last_name")
+	phone, _ := resource.GetProfileStringValue(profile, "phone")
+	license, _ := resource.GetProfileStringValue(profile, "license")
 
-	return nextPageToken, nil, nil
+	return &client.CreateUserRequest{
+		User: client.CreateUserDetails{
+			Email:     email,
+			FirstName: firstName,
+			LastName:  lastName,
+			Phone:     phone,
+		},
+		Workspaces: []client.UserWorkspaceRequest{
+			{
+				WorkspaceID: workspaceID,
+				Role:        role,
+			},
+		},
+		License: license,
+	}, nil
 }
@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,11 @@ import (
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
 	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // Test that client can fetch all users.
@@ -64,3 +70,325 @@ func TestPandaDocClient_ListUsers(t *testing.T) {
 		t.Fatal("Expected non-nil nextOptions")
 	}
 }
+
+func TestCreateUserRequest(t *testing.T) {
+	profile, err := structpb.NewStruct(map[string]interface{}{
+		"first_name":   "User3",
+		"last_name":    "Test",
+		"license":      "Full",
+		"workspace_id": "testWorkspace01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	accountInfo := &v2.AccountInfo{
+		Emails: []*v2.AccountInfo_Email{
+			{Address: "testUser03@test.com", IsPrimary: true},
+		},
+		Profile: profile,
+	}
+
+	request, err := createUserRequest(accountInfo, "Member")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if request.User.Email != "testUser03@test.com" {
+		t.Errorf("Unexpected email: got %s, want %s", request.User.Email, "testUser03@test.com")
+	}
+
+	if len(request.Workspaces) != 1 || request.Workspaces[0].WorkspaceID != "testWorkspace01" {
+		t.Fatalf("Expected user to be added to testWorkspace01, got %+v", request.Workspaces)
+	}
+
+	// The role falls back to the default role when the profile doesn't set one.
+	if request.Workspaces[0].Role != "Member" {
+		t.Errorf("Unexpected role: got %s, want %s", request.Workspaces[0].Role, "Member")
+	}
+
+	// A workspace is required to create a PandaDoc user.
+	_, err = createUserRequest(&v2.AccountInfo{Login: "testUser03@test.com"}, "Member")
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestUserBuilder_DeleteOrganizationOwner(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	builder := newUserBuilder(testClient, newDirectory(testClient), "Member")
+
+	// testUser02 is the organization owner and must never be deleted.
+	_, err := builder.Delete(ctx, &v2.ResourceId{
+		ResourceType: userResourceType.Id,
+		Resource:     "testUser02",
+	})
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+// deleteRoundTrip serves the users list and removes memberships, the workspaces in failing answer with 403.
+func deleteRoundTrip(removed *[]string, failing map[string]bool) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+
+		if req.Method == http.MethodDelete {
+			workspaceID := strings.Split(req.URL.Path, "/")[2]
+			if failing[workspaceID] {
+				resp.StatusCode = http.StatusForbidden
+				resp.Body = io.NopCloser(strings.NewReader(`{"type": "permission_error", "detail": "Permission denied"}`))
+				return resp, nil
+			}
+			*removed = append(*removed, req.URL.Path)
+			resp.StatusCode = http.StatusNoContent
+			resp.Body = io.NopCloser(strings.NewReader(""))
+		}
+
+		return resp, nil
+	}
+}
+
+func TestUserBuilder_Delete(t *testing.T) {
+	var removed []string
+	testClient := test.NewMockTestClient(deleteRoundTrip(&removed, nil))
+
+	ctx := context.Background()
+
+	builder := newUserBuilder(testClient, newDirectory(testClient), "Member")
+
+	_, err := builder.Delete(ctx, &v2.ResourceId{
+		ResourceType: userResourceType.Id,
+		Resource:     "testUser01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"/workspaces/TestWorkspace01/members/testMember01",
+		"/workspaces/TestWorkspace02/members/testMember02",
+	}
+	if len(removed) != len(expected) {
+		t.Fatalf("Expected %d memberships removed, got %v", len(expected), removed)
+	}
+	for i, path := range expected {
+		if removed[i] != path {
+			t.Errorf("Unexpected membership removed: got %s, want %s", removed[i], path)
+		}
+	}
+}
+
+func TestUserBuilder_DeletePartialFailure(t *testing.T) {
+	var removed []string
+	testClient := test.NewMockTestClient(deleteRoundTrip(&removed, map[string]bool{"TestWorkspace01": true}))
+
+	ctx := context.Background()
+
+	builder := newUserBuilder(testClient, newDirectory(testClient), "Member")
+
+	_, err := builder.Delete(ctx, &v2.ResourceId{
+		ResourceType: userResourceType.Id,
+		Resource:     "testUser01",
+	})
+
+	var removalErr *MembershipRemovalError
+	if !errors.As(err, &removalErr) {
+		t.Fatalf("Expected a MembershipRemovalError, got %v", err)
+	}
+
+	if len(removalErr.Failures) != 1 || removalErr.Failures["testMember01"] == nil {
+		t.Errorf("Expected only testMember01 to fail, got %v", removalErr.Failures)
+	}
+
+	// The other memberships are still removed.
+	if len(removed) != 1 || removed[0] != "/workspaces/TestWorkspace02/members/testMember02" {
+		t.Errorf("Expected testMember02 to be removed, got %v", removed)
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the APIError of the failure to be wrapped, got %v", err)
+	}
+}
+
+func TestMembershipRemovalError(t *testing.T) {
+	err := error(&MembershipRemovalError{
+		UserID: "testUser01",
+		Failures: map[string]error{
+			"testMember02": fmt.Errorf("workspace TestWorkspace02: permission denied"),
+			"testMember01": fmt.Errorf("workspace TestWorkspace01: permission denied"),
+		},
+	})
+
+	var removalErr *MembershipRemovalError
+	if !errors.As(err, &removalErr) {
+		t.Fatal("Expected a MembershipRemovalError")
+	}
+
+	expected := "baton-panda-doc: failed to remove user testUser01 from memberships " +
+		"testMember01: workspace TestWorkspace01: permission denied; testMember02: workspace TestWorkspace02: permission denied"
+	if err.Error() != expected {
+		t.Errorf("Unexpected error message: got %s, want %s", err.Error(), expected)
+	}
+}
+
+func TestUserBuilder_ListAllPages(t *testing.T) {
+	tests := []struct {
+		total    int
+		requests int
+	}{
+		{total: 0, requests: 1},
+		{total: 1, requests: 1},
+		{total: 50, requests: 1},
+		{total: 51, requests: 2},
+		{total: 120, requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d users", tt.total), func(t *testing.T) {
+			users := make([]any, 0, tt.total)
+			for i := range tt.total {
+				users = append(users, client.User{
+					ID:    fmt.Sprintf("testUser%03d", i),
+					Email: fmt.Sprintf("testUser%03d@test.com", i),
+				})
+			}
+
+			requests := 0
+			testClient := test.NewMockTestClient(test.PagedRoundTrip(users, &requests))
+
+			ctx := context.Background()
+
+			builder := newUserBuilder(testClient, newDirectory(testClient), "Member")
+
+			listed := 0
+			pToken := &pagination.Token{Size: client.ItemsPerPage}
+			for {
+				resources, nextPageToken, _, err := builder.List(ctx, nil, pToken)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				listed += len(resources)
+				if nextPageToken == "" {
+					break
+				}
+				pToken.Token = nextPageToken
+			}
+
+			if listed != tt.total {
+				t.Errorf("Expected Count to be %d, got %d", tt.total, listed)
+			}
+
+			if requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, requests)
+			}
+		})
+	}
+}
+
+func TestPandaDocClient_ListUsersRateLimited(t *testing.T) {
+	requests := 0
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+
+		// The first request hits the rate limit.
+		if requests == 1 {
+			resp := &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader("")),
+			}
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		}
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		resp.Header.Set("X-Ratelimit-Limit", "100")
+		resp.Header.Set("X-Ratelimit-Remaining", "99")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	result, _, annos, err := testClient.ListUsers(ctx, client.PageOptions{
+		Count: 50,
+		Page:  1,
+	})
+
+	// Check for errors.
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+
+	if len(result) != 2 {
+		t.Errorf("Expected Count to be 2, got %d", len(result))
+	}
+
+	var ratelimitData v2.RateLimitDescription
+	ok, err := annos.Pick(&ratelimitData)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !ok || ratelimitData.Remaining != 99 {
+		t.Errorf("Unexpected rate limit annotation: %v", &ratelimitData)
+	}
+}
+
+func TestPandaDocClient_ListUsersAPIError(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{"type": "authentication_error", "detail": "API key revoked"}`)),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	_, _, _, err := testClient.ListUsers(ctx, client.PageOptions{
+		Count: 50,
+		Page:  1,
+	})
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+
+	if apiErr.Type != "authentication_error" || apiErr.Detail != "API key revoked" {
+		t.Errorf("Unexpected error: got %s", apiErr)
+	}
+
+	if code := status.Code(err); code != codes.Unauthenticated {
+		t.Errorf("Unexpected code: got %s, want %s", code, codes.Unauthenticated)
+	}
+}
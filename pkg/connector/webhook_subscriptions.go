@@ -0,0 +1,137 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type webhookSubscriptionBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.PandaDocClient
+}
+
+func (wb *webhookSubscriptionBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return wb.resourceType
+}
+
+// List returns the webhook subscriptions of the workspace the credentials belong to.
+// PandaDoc has no way to list the subscriptions of the other workspaces, they are not synced.
+func (wb *webhookSubscriptionBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var resources []*v2.Resource
+	if parentResourceID != nil {
+		return nil, "", nil, nil
+	}
+
+	subscriptions, annotation, err := wb.client.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-panda-doc: failed to list webhook subscriptions: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		subscriptionResource, err := parseIntoWebhookSubscriptionResource(&subscription)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		resources = append(resources, subscriptionResource)
+	}
+
+	return resources, "", annotation, nil
+}
+
+// This function parses a webhook subscription from PandaDoc into a secret resource, the shared key is the secret.
+// The secret belongs to the workspace that created the subscription, its profile carries where data is sent to.
+func parseIntoWebhookSubscriptionResource(subscription *client.WebhookSubscription) (*v2.Resource, error) {
+	status := subscription.Status
+	if status == "" {
+		status = "inactive"
+		if subscription.Active {
+			status = "active"
+		}
+	}
+	status = strings.ToLower(status)
+
+	var host string
+	if subscriptionURL, err := url.Parse(subscription.URL); err == nil {
+		host = subscriptionURL.Hostname()
+	}
+
+	triggers := make([]interface{}, 0, len(subscription.Triggers))
+	for _, trigger := range subscription.Triggers {
+		triggers = append(triggers, trigger)
+	}
+
+	profile := map[string]interface{}{
+		"url":          subscription.URL,
+		"host":         host,
+		"triggers":     triggers,
+		"status":       status,
+		"workspace_id": subscription.WorkspaceID,
+	}
+
+	secretOptions := []resource.SecretTraitOption{
+		withSecretProfile(profile),
+	}
+	if subscription.WorkspaceID != "" {
+		secretOptions = append(secretOptions, resource.WithSecretIdentityID(&v2.ResourceId{
+			ResourceType: workspaceResourceType.Id,
+			Resource:     subscription.WorkspaceID,
+		}))
+	}
+
+	name := subscription.Name
+	if name == "" {
+		name = subscription.URL
+	}
+
+	ret, err := resource.NewSecretResource(
+		name,
+		webhookSubscriptionResourceType,
+		subscription.ID,
+		secretOptions,
+		resource.WithDescription(fmt.Sprintf("Sends %s to %s (%s)", strings.Join(subscription.Triggers, ", "), subscription.URL, status)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// withSecretProfile sets the profile of the secret trait, the SDK has no option for it.
+func withSecretProfile(profile map[string]interface{}) resource.SecretTraitOption {
+	return func(t *v2.SecretTrait) error {
+		p, err := structpb.NewStruct(profile)
+		if err != nil {
+			return err
+		}
+
+		t.Profile = p
+
+		return nil
+	}
+}
+
+// Webhook subscriptions grant no access, they only send data out.
+func (wb *webhookSubscriptionBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (wb *webhookSubscriptionBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newWebhookSubscriptionBuilder(client *client.PandaDocClient) *webhookSubscriptionBuilder {
+	return &webhookSubscriptionBuilder{
+		resourceType: webhookSubscriptionResourceType,
+		client:       client,
+	}
+}
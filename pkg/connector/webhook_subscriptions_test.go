@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestWebhookSubscriptionBuilder_List(t *testing.T) {
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_webhook_subscriptions.json"))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	ctx := context.Background()
+
+	builder := newWebhookSubscriptionBuilder(testClient)
+
+	subscriptions, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(subscriptions) != 1 {
+		t.Fatalf("Expected Count to be 1, got %d", len(subscriptions))
+	}
+
+	subscription := subscriptions[0]
+	if subscription.Id.Resource != "testWebhook01" || subscription.DisplayName != "Document updates" {
+		t.Errorf("Unexpected subscription: %v", subscription)
+	}
+
+	expectedDescription := "Sends document_state_changed, recipient_completed to https://hooks.example.com/pandadoc (active)"
+	if subscription.Description != expectedDescription {
+		t.Errorf("Unexpected description: got %s, want %s", subscription.Description, expectedDescription)
+	}
+
+	if strings.Contains(subscription.String(), "testSharedKey") {
+		t.Errorf("Expected the shared key not to be synced")
+	}
+
+	subscriptionAnnotations := annotations.Annotations(subscription.Annotations)
+	secretTrait := &v2.SecretTrait{}
+	ok, err := subscriptionAnnotations.Pick(secretTrait)
+	if err != nil || !ok {
+		t.Fatalf("Expected a secret trait, got %v", err)
+	}
+
+	if secretTrait.IdentityId.GetResource() != "testWorkspace01" {
+		t.Errorf("Unexpected identity: got %s, want testWorkspace01", secretTrait.IdentityId.GetResource())
+	}
+
+	profile := secretTrait.GetProfile().AsMap()
+	expectedProfile := map[string]interface{}{
+		"url":          "https://hooks.example.com/pandadoc",
+		"host":         "hooks.example.com",
+		"triggers":     []interface{}{"document_state_changed", "recipient_completed"},
+		"status":       "active",
+		"workspace_id": "testWorkspace01",
+	}
+	if !reflect.DeepEqual(profile, expectedProfile) {
+		t.Errorf("Unexpected profile: got %v, want %v", profile, expectedProfile)
+	}
+
+	workspaceSubscriptions, _, _, err := builder.List(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "testWorkspace01"}, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(workspaceSubscriptions) != 0 {
+		t.Errorf("Expected no subscriptions under a workspace, got %d", len(workspaceSubscriptions))
+	}
+}
@@ -0,0 +1,279 @@
+package connector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-panda-doc/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PandaDoc webhook events that change who has access to a document.
+const (
+	webhookDocumentStateChanged = "document_state_changed"
+	webhookDocumentUpdated      = "document_updated"
+	webhookDocumentDeleted      = "document_deleted"
+	webhookRecipientCompleted   = "recipient_completed"
+
+	// Deliveries are small, larger bodies are rejected before being read.
+	maxWebhookBodySize = 1 << 20
+)
+
+// EventQueue receives the events of the verified webhook deliveries.
+type EventQueue interface {
+	Push(ctx context.Context, events []*v2.Event) error
+}
+
+// FileEventQueue appends the events to the file at the given path, one JSON encoded event per line.
+type FileEventQueue struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewFileEventQueue(path string) *FileEventQueue {
+	return &FileEventQueue{path: path}
+}
+
+func (q *FileEventQueue) Push(_ context.Context, events []*v2.Event) error {
+	var lines []byte
+	for _, event := range events {
+		line, err := protojson.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("baton-panda-doc: failed to encode event %s: %w", event.Id, err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	file, err := os.OpenFile(q.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("baton-panda-doc: failed to open event queue %s: %w", q.path, err)
+	}
+
+	_, err = file.Write(lines)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("baton-panda-doc: failed to write event queue %s: %w", q.path, err)
+	}
+
+	return file.Close()
+}
+
+// WebhookHandler receives PandaDoc webhook deliveries. Each delivery is signed with the shared key of the webhook,
+// the HMAC-SHA256 of the body is passed hex-encoded in the signature query parameter.
+// The owner and the recipients are looked up in the users of the organization, the way document grants are.
+type WebhookHandler struct {
+	sharedKey []byte
+	queue     EventQueue
+	directory *directory
+	now       func() time.Time
+}
+
+// NewWebhookHandler returns a handler verifying deliveries with sharedKey and pushing their events to queue,
+// auth is either client.WithBearerToken or client.WithOAuth2.
+func NewWebhookHandler(ctx context.Context, domain string, auth client.Option, sharedKey string, queue EventQueue) (*WebhookHandler, error) {
+	pandaDocClient, err := client.New(
+		ctx,
+		client.WithDomain(domain),
+		auth,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookHandler(pandaDocClient, sharedKey, queue), nil
+}
+
+func newWebhookHandler(c *client.PandaDocClient, sharedKey string, queue EventQueue) *WebhookHandler {
+	return &WebhookHandler{
+		sharedKey: []byte(sharedKey),
+		queue:     queue,
+		directory: newDirectory(c),
+		now:       time.Now,
+	}
+}
+
+// webhookEvent is a single event of a delivery, a delivery holds a list of them.
+type webhookEvent struct {
+	Event string          `json:"event"`
+	Data  webhookDocument `json:"data"`
+}
+
+// webhookDocument is the document the event is about. Recipient events carry the recipient that acted in ActionBy.
+type webhookDocument struct {
+	client.Document
+	CreatedBy  client.DocumentUser        `json:"created_by"`
+	Recipients []client.DocumentRecipient `json:"recipients"`
+	ActionBy   *client.DocumentRecipient  `json:"action_by,omitempty"`
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := ctxzap.Extract(ctx)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.verifySignature(body, r.URL.Query().Get("signature")) {
+		l.Warn("baton-panda-doc: rejecting webhook delivery with an invalid signature", zap.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var deliveries []webhookEvent
+	err = json.Unmarshal(body, &deliveries)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	users, err := h.directory.Users(ctx)
+	if err != nil {
+		l.Error("baton-panda-doc: failed to list users for webhook events", zap.Error(err))
+		http.Error(w, "failed to list users", http.StatusInternalServerError)
+		return
+	}
+
+	var events []*v2.Event
+	for index, delivery := range deliveries {
+		events = append(events, webhookEvents(&delivery, users, fmt.Sprintf("%x:%d", sha256.Sum256(body), index), h.now())...)
+	}
+
+	if len(events) > 0 {
+		err = h.queue.Push(ctx, events)
+		if err != nil {
+			l.Error("baton-panda-doc: failed to queue webhook events", zap.Error(err))
+			// PandaDoc retries deliveries that failed.
+			http.Error(w, "failed to queue events", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verifySignature checks the hex-encoded HMAC-SHA256 of the body, compared in constant time.
+func (h *WebhookHandler) verifySignature(body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, h.sharedKey)
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// webhookEvents converts a webhook event into baton events. Document changes grant the owner and the recipients
+// their access again, deleted documents revoke it and completed recipients are reported as usage of the document.
+// Recipients sharing their email with a user are that user, the others are their contact.
+func webhookEvents(delivery *webhookEvent, users []client.User, id string, receivedAt time.Time) []*v2.Event {
+	document := &delivery.Data
+	if document.ID == "" {
+		return nil
+	}
+
+	documentResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: documentResourceType.Id,
+			Resource:     document.ID,
+		},
+		DisplayName: document.Name,
+	}
+
+	occurredAt := document.DateModified
+	if occurredAt.IsZero() {
+		occurredAt = receivedAt
+	}
+
+	newEvent := func(index int) *v2.Event {
+		return &v2.Event{
+			Id:         fmt.Sprintf("%s:%d", id, index),
+			OccurredAt: timestamppb.New(occurredAt),
+		}
+	}
+
+	type access struct {
+		entitlementName string
+		principal       *v2.ResourceId
+	}
+	var accesses []access
+	if owner := documentOwnerPrincipal(users, &document.CreatedBy); owner != nil {
+		accesses = append(accesses, access{documentOwner, owner})
+	}
+	for _, recipient := range document.Recipients {
+		if principal := documentRecipientPrincipal(users, &recipient); principal != nil {
+			accesses = append(accesses, access{documentRecipient, principal})
+		}
+	}
+
+	var events []*v2.Event
+	switch delivery.Event {
+	case webhookDocumentStateChanged, webhookDocumentUpdated:
+		for _, a := range accesses {
+			event := newEvent(len(events))
+			event.Event = &v2.Event_GrantEvent{
+				GrantEvent: &v2.GrantEvent{
+					Grant: grant.NewGrant(documentResource, a.entitlementName, a.principal),
+				},
+			}
+			events = append(events, event)
+		}
+	case webhookDocumentDeleted:
+		for _, a := range accesses {
+			event := newEvent(len(events))
+			event.Event = &v2.Event_RevokeEvent{
+				RevokeEvent: &v2.RevokeEvent{
+					Entitlement: entitlement.NewPermissionEntitlement(documentResource, a.entitlementName),
+					Principal:   &v2.Resource{Id: a.principal},
+				},
+			}
+			events = append(events, event)
+		}
+	case webhookRecipientCompleted:
+		if document.ActionBy == nil {
+			return nil
+		}
+		actor := documentRecipientPrincipal(users, document.ActionBy)
+		if actor == nil {
+			return nil
+		}
+		event := newEvent(0)
+		event.Event = &v2.Event_UsageEvent{
+			UsageEvent: &v2.UsageEvent{
+				TargetResource: documentResource,
+				ActorResource:  &v2.Resource{Id: actor},
+			},
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,187 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const testWebhookPayload = `[
+	{
+		"event": "document_state_changed",
+		"data": {
+			"id": "testDocument01",
+			"name": "Test Document",
+			"status": "document.sent",
+			"date_modified": "2025-03-12T10:00:00Z",
+			"created_by": {"id": "testCreator01", "email": "TestUser01@test.com"},
+			"recipients": [
+				{"email": "testUser02@test.com", "contact_id": "testContact02"},
+				{"email": "contact@example.com", "contact_id": "testContact01"}
+			]
+		}
+	},
+	{
+		"event": "recipient_completed",
+		"data": {
+			"id": "testDocument01",
+			"name": "Test Document",
+			"action_by": {"email": "contact@example.com", "contact_id": "testContact01"}
+		}
+	},
+	{
+		"event": "document_deleted",
+		"data": {
+			"id": "testDocument02",
+			"created_by": {"id": "testUser01"}
+		}
+	},
+	{
+		"event": "document_creation_failed",
+		"data": {"id": "testDocument03"}
+	}
+]`
+
+// newTestWebhookHandler returns a handler looking the owner and the recipients up in the mocked users.
+func newTestWebhookHandler(sharedKey string, queue EventQueue) *WebhookHandler {
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	return newWebhookHandler(testClient, sharedKey, queue)
+}
+
+// sendWebhook delivers the payload the way PandaDoc does, signed with the shared key.
+func sendWebhook(t *testing.T, server *httptest.Server, sharedKey, payload string) *http.Response {
+	mac := hmac.New(sha256.New, []byte(sharedKey))
+	mac.Write([]byte(payload))
+
+	resp, err := http.Post(server.URL+"?signature="+hex.EncodeToString(mac.Sum(nil)), "application/json", strings.NewReader(payload))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	return resp
+}
+
+func TestWebhookHandler_QueuesEvents(t *testing.T) {
+	queuePath := filepath.Join(t.TempDir(), "events.jsonl")
+	server := httptest.NewServer(newTestWebhookHandler("testKey", NewFileEventQueue(queuePath)))
+	defer server.Close()
+
+	resp := sendWebhook(t, server, "testKey", testWebhookPayload)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status: got %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	file, err := os.Open(queuePath)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer file.Close()
+
+	var events []*v2.Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := &v2.Event{}
+		err := protojson.Unmarshal(scanner.Bytes(), event)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 events, got %d", len(events))
+	}
+
+	// The owner and the recipients sharing their email with a user are granted as that user.
+	grants := []string{
+		"document:testDocument01:owner:user:testUser01",
+		"document:testDocument01:recipient:user:testUser02",
+		"document:testDocument01:recipient:contact:testContact01",
+	}
+	for i, id := range grants {
+		grantEvent := events[i].GetGrantEvent()
+		if grantEvent == nil || grantEvent.Grant.Id != id {
+			t.Errorf("Unexpected event %d: got %v, want grant %s", i, events[i].Event, id)
+		}
+	}
+
+	usage := events[3].GetUsageEvent()
+	if usage == nil || usage.TargetResource.Id.Resource != "testDocument01" || usage.ActorResource.Id.Resource != "testContact01" {
+		t.Errorf("Unexpected usage event: %v", events[3].Event)
+	}
+
+	revoke := events[4].GetRevokeEvent()
+	if revoke == nil || revoke.Entitlement.Id != "document:testDocument02:owner" || revoke.Principal.Id.Resource != "testUser01" {
+		t.Errorf("Unexpected revoke event: %v", events[4].Event)
+	}
+}
+
+type recordingQueue struct {
+	events []*v2.Event
+}
+
+func (q *recordingQueue) Push(_ context.Context, events []*v2.Event) error {
+	q.events = append(q.events, events...)
+	return nil
+}
+
+func TestWebhookHandler_RejectsDeliveries(t *testing.T) {
+	queue := &recordingQueue{}
+	server := httptest.NewServer(newTestWebhookHandler("testKey", queue))
+	defer server.Close()
+
+	resp := sendWebhook(t, server, "otherKey", testWebhookPayload)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Unexpected status for a wrong key: got %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(testWebhookPayload))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Unexpected status for a missing signature: got %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	resp = sendWebhook(t, server, "testKey", `{"event": "document_updated"}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status for an invalid payload: got %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	resp, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected status for a GET: got %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	if len(queue.events) != 0 {
+		t.Errorf("Expected no queued events, got %d", len(queue.events))
+	}
+}
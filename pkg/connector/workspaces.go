@@ -2,7 +2,8 @@ package connector
 
 import (
 	"context"
-	"sync"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
@@ -12,23 +13,33 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 type workspaceBuilder struct {
 	resourceType *v2.ResourceType
 	client       *client.PandaDocClient
-	users        []client.User
-	usersMutex   sync.RWMutex
+	defaultRole  string
+	directory    *directory
 }
 
 var permissionName = "member"
 
+// Role given to the initial owner of workspaces created by the connector.
+var workspaceOwnerRole = "Admin"
+
 func (wb *workspaceBuilder) ResourceType(_ context.Context) *v2.ResourceType {
 	return workspaceResourceType
 }
 
+// List returns the workspaces of the organization, workspaces are only listed under their organization.
 func (wb *workspaceBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
 	var resources []*v2.Resource
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
 	bag, pageToken, err := getToken(pToken, workspaceResourceType)
 
 	if err != nil {
@@ -48,19 +59,24 @@ func (wb *workspaceBuilder) List(ctx context.Context, parentResourceID *v2.Resou
 		return nil, "", nil, err
 	}
 
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	for _, workspace := range workspaces {
-		workspaceResource, err := parseIntoWorkspaceResource(workspace)
+		workspaceResource, err := parseIntoWorkspaceResource(workspace, parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
 		resources = append(resources, workspaceResource)
 	}
 
-	return resources, "", annotation, nil
+	return resources, nextPageToken, annotation, nil
 }
 
 // This function parses a workspace from PandaDoc into a Workspace Resource.
-func parseIntoWorkspaceResource(workspace client.Workspace) (*v2.Resource, error) {
+func parseIntoWorkspaceResource(workspace client.Workspace, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"workspace_id": workspace.ID,
 		"name":         workspace.Name,
@@ -77,6 +93,11 @@ func parseIntoWorkspaceResource(workspace client.Workspace) (*v2.Resource, error
 		workspaceResourceType,
 		workspace.ID,
 		groupTraits,
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: roleResourceType.Id}),
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: documentFolderResourceType.Id}),
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: templateFolderResourceType.Id}),
+		resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: apiKeyResourceType.Id}),
 	)
 
 	if err != nil {
@@ -86,6 +107,8 @@ func parseIntoWorkspaceResource(workspace client.Workspace) (*v2.Resource, error
 	return ret, nil
 }
 
+// Entitlements returns the membership of the workspace, only users can be granted it.
+// Role holders are members through the expansion of the role grants, not through a grant of their own.
 func (wb *workspaceBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var entitlements []*v2.Entitlement
 
@@ -100,78 +123,202 @@ func (wb *workspaceBuilder) Entitlements(_ context.Context, resource *v2.Resourc
 	return entitlements, "", nil, nil
 }
 
+// Grants returns a membership grant for every role held in the workspace, expanded to the holders of the role.
+// Every member holds exactly one role in the workspace, so membership is derived from role grants.
+// The users are returned in pages, a role is granted on the page of the first user holding it.
 func (wb *workspaceBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	var grants []*v2.Grant
 
 	var workspaceId = resource.Id.Resource
 
-	err := wb.GetUsers(ctx)
+	bag, offset, err := getToken(pToken, userResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
 
+	users, err := wb.directory.Users(ctx)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	users := wb.users
+	start, end, nextPage := pageBounds(offset, pToken.Size, len(users))
 
-	for _, user := range users {
+	var roles, pageRoles []string
+	for index, user := range users[:end] {
 		for _, workspace := range user.Workspaces {
-			if workspace.WorkspaceID == workspaceId {
-				userResource, _ := parseIntoUserResource(ctx, &user, resource.Id)
-				membershipGrant := grant.NewGrant(resource, permissionName, userResource)
-				grants = append(grants, membershipGrant)
+			if workspace.WorkspaceID != workspaceId || slices.Contains(roles, workspace.Role) {
+				continue
+			}
+			roles = append(roles, workspace.Role)
+			if index >= start {
+				pageRoles = append(pageRoles, workspace.Role)
 			}
 		}
 	}
-	return grants, "", nil, nil
+
+	for _, role := range pageRoles {
+		roleResource := &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: roleResourceType.Id,
+				Resource:     roleID(workspaceId, role),
+			},
+		}
+		membershipGrant := grant.NewGrant(resource, permissionName, roleResource.Id, grant.WithAnnotation(&v2.GrantExpandable{
+			EntitlementIds: []string{entitlement.NewEntitlementID(roleResource, roleAssignment)},
+		}))
+		grants = append(grants, membershipGrant)
+	}
+
+	err = bag.Next(nextPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return grants, nextPageToken, nil, nil
 }
 
-func newWorkspaceBuilder(client *client.PandaDocClient) *workspaceBuilder {
-	return &workspaceBuilder{
-		resourceType: workspaceResourceType,
-		client:       client,
+// Grant adds the user to the workspace with the default role, syncs expand the membership from that role.
+func (wb *workspaceBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"baton-panda-doc: only users can be granted workspace membership",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, nil, fmt.Errorf("baton-panda-doc: only users can be granted workspace membership")
+	}
+
+	workspaceID := entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	membership, err := wb.directory.Membership(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if membership != nil {
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	annotation, err := wb.client.AddWorkspaceMember(ctx, workspaceID, client.WorkspaceMemberRequest{
+		UserID: userID,
+		Role:   wb.defaultRole,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-panda-doc: failed to add user %s to workspace %s: %w", userID, workspaceID, err)
 	}
+	wb.directory.Invalidate()
+
+	membershipGrant := grant.NewGrant(entitlement.Resource, permissionName, principal.Id)
+
+	return []*v2.Grant{membershipGrant}, annotation, nil
 }
 
-func (wb *workspaceBuilder) GetUsers(ctx context.Context) error {
-	wb.usersMutex.RLock()
-	defer wb.usersMutex.RUnlock()
+func (wb *workspaceBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := grant.Principal
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"baton-panda-doc: only users can have workspace membership revoked",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("baton-panda-doc: only users can have workspace membership revoked")
+	}
 
-	paginationToken := pagination.Token{
-		Size:  50,
-		Token: "",
+	workspaceID := grant.Entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	membership, err := wb.directory.Membership(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if wb.users != nil {
-		return nil
+	if membership == nil {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
 	}
 
-	for {
-		bag, pageToken, err := getToken(&paginationToken, userResourceType)
-		if err != nil {
-			return err
-		}
-		users, nextPageToken, _, err := wb.client.ListUsers(ctx, client.PageOptions{
-			Count: paginationToken.Size,
-			Page:  pageToken,
+	annotation, err := wb.client.RemoveWorkspaceMember(ctx, workspaceID, membership.MembershipID)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: failed to remove user %s from workspace %s: %w", userID, workspaceID, err)
+	}
+	wb.directory.Invalidate()
+
+	return annotation, nil
+}
+
+// Create creates a workspace named after the resource display name. When the group profile
+// carries an owner user ID, that user is added to the workspace as its admin.
+func (wb *workspaceBuilder) Create(ctx context.Context, newWorkspace *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	name := newWorkspace.GetDisplayName()
+	if name == "" {
+		return nil, nil, fmt.Errorf("baton-panda-doc: a display name is required to create a workspace")
+	}
+
+	var ownerID string
+	groupTrait, err := resource.GetGroupTrait(newWorkspace)
+	if err == nil {
+		ownerID, _ = resource.GetProfileStringValue(groupTrait.GetProfile(), "owner")
+	}
+
+	workspace, annotation, err := wb.client.CreateWorkspace(ctx, client.CreateWorkspaceRequest{
+		Name: name,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-panda-doc: failed to create workspace %s: %w", name, err)
+	}
+
+	if ownerID != "" {
+		ownerAnnotation, err := wb.client.AddWorkspaceMember(ctx, workspace.ID, client.WorkspaceMemberRequest{
+			UserID: ownerID,
+			Role:   workspaceOwnerRole,
 		})
 		if err != nil {
-			return err
-		}
-		err = bag.Next(nextPageToken)
-		if err != nil {
-			return err
+			return nil, nil, fmt.Errorf("baton-panda-doc: workspace %s created but failed to add owner %s: %w", workspace.ID, ownerID, err)
 		}
+		annotation = append(annotation, ownerAnnotation...)
+		wb.directory.Invalidate()
+	}
 
-		wb.users = append(wb.users, users...)
-		nextPageToken, err = bag.Marshal()
-		if err != nil {
-			return err
-		}
-		if nextPageToken == "" {
-			break
-		}
-		paginationToken.Token = nextPageToken
+	workspaceResource, err := parseIntoWorkspaceResource(*workspace, &v2.ResourceId{
+		ResourceType: organizationResourceType.Id,
+		Resource:     organizationID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return workspaceResource, annotation, nil
+}
+
+// Delete deactivates the workspace, PandaDoc doesn't allow workspaces to be removed.
+func (wb *workspaceBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != workspaceResourceType.Id {
+		return nil, fmt.Errorf("baton-panda-doc: only workspaces can be deleted by the workspace builder")
 	}
 
-	return nil
+	annotation, err := wb.client.DeactivateWorkspace(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("baton-panda-doc: failed to deactivate workspace %s: %w", resourceId.Resource, err)
+	}
+	wb.directory.Invalidate()
+
+	return annotation, nil
+}
+
+func newWorkspaceBuilder(client *client.PandaDocClient, directory *directory, defaultRole string) *workspaceBuilder {
+	return &workspaceBuilder{
+		resourceType: workspaceResourceType,
+		client:       client,
+		directory:    directory,
+		defaultRole:  defaultRole,
+	}
 }
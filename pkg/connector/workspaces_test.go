@@ -10,6 +10,10 @@ import (
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
 	"github.com/conductorone/baton-panda-doc/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
 )
 
 func TestPandaDocClient_ListWorkspaces(t *testing.T) {
@@ -59,3 +63,486 @@ func TestPandaDocClient_ListWorkspaces(t *testing.T) {
 		t.Fatal("Expected non-nil nextOptions")
 	}
 }
+
+func TestPandaDocClient_AddWorkspaceMember(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	_, err := testClient.AddWorkspaceMember(ctx, "testWorkspace01", client.WorkspaceMemberRequest{
+		UserID: "testUser01",
+		Role:   "Member",
+	})
+
+	// Check for errors.
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestPandaDocClient_RemoveWorkspaceMember(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusNotFound,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	_, err := testClient.RemoveWorkspaceMember(ctx, "testWorkspace01", "testMember01")
+
+	// A missing membership must surface as an error.
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestPandaDocClient_CreateWorkspace(t *testing.T) {
+	// Create a mock response.
+	mockResponse := &http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{"id": "testWorkspace03", "name": "EMEA Sales"}`)),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+	// Create a test client with the mock response.
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	workspace, _, err := testClient.CreateWorkspace(ctx, client.CreateWorkspaceRequest{
+		Name: "EMEA Sales",
+	})
+
+	// Check for errors.
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if workspace.ID != "testWorkspace03" {
+		t.Errorf("Unexpected workspace: got %+v, want %+v", workspace.ID, "testWorkspace03")
+	}
+}
+
+func TestWorkspaceBuilder_CreateWithoutName(t *testing.T) {
+	testClient := test.NewTestClient(nil, nil)
+
+	ctx := context.Background()
+
+	builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+	// Workspaces are created from the display name, so it can't be empty.
+	_, _, err := builder.Create(ctx, &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: workspaceResourceType.Id,
+		},
+	})
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+}
+
+func TestWorkspaceBuilder_GrantsExpandFromRoles(t *testing.T) {
+	mockResponse := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(test.ReadFile("mock_users.json"))),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+	testClient := test.NewTestClient(mockResponse, nil)
+
+	ctx := context.Background()
+
+	builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+	workspaceResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: workspaceResourceType.Id,
+			Resource:     "TestWorkspace02",
+		},
+	}
+
+	grants, _, _, err := builder.Grants(ctx, workspaceResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"workspace:TestWorkspace02:member:role:TestWorkspace02:Custom Role": "role:TestWorkspace02:Custom Role:assigned",
+		"workspace:TestWorkspace02:member:role:TestWorkspace02:Admin":       "role:TestWorkspace02:Admin:assigned",
+	}
+	if len(grants) != len(expected) {
+		t.Fatalf("Expected %d grants, got %d", len(expected), len(grants))
+	}
+
+	for _, membershipGrant := range grants {
+		roleEntitlementID, ok := expected[membershipGrant.Id]
+		if !ok {
+			t.Errorf("Unexpected grant %s", membershipGrant.Id)
+			continue
+		}
+
+		expandable := &v2.GrantExpandable{}
+		grantAnnotations := annotations.Annotations(membershipGrant.Annotations)
+		ok, err := grantAnnotations.Pick(expandable)
+		if err != nil || !ok {
+			t.Fatalf("Expected grant %s to be expandable, got %v", membershipGrant.Id, err)
+		}
+		if len(expandable.EntitlementIds) != 1 || expandable.EntitlementIds[0] != roleEntitlementID {
+			t.Errorf("Unexpected expandable entitlements for %s: %v", membershipGrant.Id, expandable.EntitlementIds)
+		}
+	}
+}
+
+func TestWorkspaceBuilder_GrantsPages(t *testing.T) {
+	users := make([]any, 0, 120)
+	for i := range 120 {
+		role := "Member"
+		if i >= 60 {
+			role = "Admin"
+		}
+		users = append(users, client.User{
+			ID:    fmt.Sprintf("testUser%03d", i),
+			Email: fmt.Sprintf("testUser%03d@test.com", i),
+			Workspaces: []client.Membership{
+				{
+					Role:         role,
+					WorkspaceID:  "testWorkspace01",
+					MembershipID: fmt.Sprintf("testMember%03d", i),
+				},
+			},
+		})
+	}
+
+	requests := 0
+	testClient := test.NewMockTestClient(test.PagedRoundTrip(users, &requests))
+
+	ctx := context.Background()
+
+	builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+	workspaceResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: workspaceResourceType.Id,
+			Resource:     "testWorkspace01",
+		},
+	}
+
+	var pages [][]string
+	pToken := &pagination.Token{Size: 50}
+	for {
+		grants, nextPageToken, _, err := builder.Grants(ctx, workspaceResource, pToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		var roles []string
+		for _, membershipGrant := range grants {
+			roles = append(roles, membershipGrant.Principal.Id.Resource)
+		}
+		pages = append(pages, roles)
+
+		if nextPageToken == "" {
+			break
+		}
+		pToken.Token = nextPageToken
+	}
+
+	// Each role is granted once, on the page of the first user holding it.
+	expected := "[[testWorkspace01:Member] [testWorkspace01:Admin] []]"
+	if fmt.Sprint(pages) != expected {
+		t.Errorf("Unexpected pages: got %v, want %s", pages, expected)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestWorkspaceBuilder_ListAllPages(t *testing.T) {
+	tests := []struct {
+		total    int
+		requests int
+	}{
+		{total: 0, requests: 1},
+		{total: 1, requests: 1},
+		{total: 50, requests: 1},
+		{total: 51, requests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d workspaces", tt.total), func(t *testing.T) {
+			workspaces := make([]any, 0, tt.total)
+			for i := range tt.total {
+				workspaces = append(workspaces, client.Workspace{
+					ID:   fmt.Sprintf("testWorkspace%03d", i),
+					Name: fmt.Sprintf("test%03d", i),
+				})
+			}
+
+			requests := 0
+			testClient := test.NewMockTestClient(test.PagedRoundTrip(workspaces, &requests))
+
+			ctx := context.Background()
+
+			builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+			organization := &v2.ResourceId{
+				ResourceType: organizationResourceType.Id,
+				Resource:     organizationID,
+			}
+
+			listed := 0
+			pToken := &pagination.Token{Size: client.ItemsPerPage}
+			for {
+				resources, nextPageToken, _, err := builder.List(ctx, organization, pToken)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				listed += len(resources)
+				if nextPageToken == "" {
+					break
+				}
+				pToken.Token = nextPageToken
+			}
+
+			if listed != tt.total {
+				t.Errorf("Expected Count to be %d, got %d", tt.total, listed)
+			}
+
+			if requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, requests)
+			}
+		})
+	}
+}
+
+func workspaceEntitlement(workspaceID string) *v2.Entitlement {
+	return &v2.Entitlement{
+		Resource: &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: workspaceResourceType.Id,
+				Resource:     workspaceID,
+			},
+		},
+	}
+}
+
+func TestWorkspaceBuilder_Grant(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace string
+		expected  []string
+		exists    bool
+	}{
+		// New members are added with the default role.
+		{
+			name:      "add member",
+			workspace: "TestWorkspace03",
+			expected:  []string{`POST /workspaces/TestWorkspace03/members {"user_id":"testUser01","role":"Member"}`},
+		},
+		{
+			name:      "already member",
+			workspace: "TestWorkspace01",
+			exists:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			testClient := test.NewMockTestClient(writesRoundTrip(&writes))
+
+			ctx := context.Background()
+
+			builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+			principal := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "testUser01"}}
+			grants, annos, err := builder.Grant(ctx, principal, workspaceEntitlement(tt.workspace))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if fmt.Sprint(writes) != fmt.Sprint(tt.expected) {
+				t.Errorf("Unexpected requests: got %v, want %v", writes, tt.expected)
+			}
+
+			if tt.exists {
+				if len(grants) != 0 || !annos.Contains(&v2.GrantAlreadyExists{}) {
+					t.Errorf("Expected the grant to already exist, got %v", grants)
+				}
+				return
+			}
+
+			if len(grants) != 1 || grants[0].Principal.Id.Resource != "testUser01" {
+				t.Errorf("Expected a grant for testUser01, got %v", grants)
+			}
+		})
+	}
+}
+
+func TestWorkspaceBuilder_Revoke(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace string
+		expected  []string
+		revoked   bool
+	}{
+		{
+			name:      "remove member",
+			workspace: "TestWorkspace01",
+			expected:  []string{"DELETE /workspaces/TestWorkspace01/members/testMember01"},
+		},
+		{
+			name:      "not a member",
+			workspace: "TestWorkspace03",
+			revoked:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writes []string
+			testClient := test.NewMockTestClient(writesRoundTrip(&writes))
+
+			ctx := context.Background()
+
+			builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+			annos, err := builder.Revoke(ctx, &v2.Grant{
+				Entitlement: workspaceEntitlement(tt.workspace),
+				Principal:   &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "testUser01"}},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if fmt.Sprint(writes) != fmt.Sprint(tt.expected) {
+				t.Errorf("Unexpected requests: got %v, want %v", writes, tt.expected)
+			}
+
+			if tt.revoked && !annos.Contains(&v2.GrantAlreadyRevoked{}) {
+				t.Errorf("Expected the grant to already be revoked")
+			}
+		})
+	}
+}
+
+func TestWorkspaceBuilder_CreateWithOwner(t *testing.T) {
+	var writes []string
+	recordWrite := writesRoundTrip(&writes)
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		resp, err := recordWrite(req)
+		if err == nil && req.Method == http.MethodPost && req.URL.Path == "/workspaces" {
+			resp.StatusCode = http.StatusCreated
+			resp.Body = io.NopCloser(strings.NewReader(`{"id": "testWorkspace03", "name": "EMEA Sales"}`))
+		}
+		return resp, err
+	})
+
+	ctx := context.Background()
+
+	builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+	newWorkspace, err := resource.NewGroupResource("EMEA Sales", workspaceResourceType, "EMEA Sales", []resource.GroupTraitOption{
+		resource.WithGroupProfile(map[string]interface{}{"owner": "testUser02"}),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	workspaceResource, _, err := builder.Create(ctx, newWorkspace)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if workspaceResource.Id.Resource != "testWorkspace03" {
+		t.Errorf("Unexpected workspace: got %+v, want %+v", workspaceResource.Id.Resource, "testWorkspace03")
+	}
+
+	// The owner is added to the new workspace as its admin.
+	expected := []string{
+		`POST /workspaces {"name":"EMEA Sales"}`,
+		`POST /workspaces/testWorkspace03/members {"user_id":"testUser02","role":"Admin"}`,
+	}
+	if fmt.Sprint(writes) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected requests: got %v, want %v", writes, expected)
+	}
+}
+
+func TestWorkspaceBuilder_Delete(t *testing.T) {
+	var writes []string
+	usersRequests := 0
+	recordWrite := writesRoundTrip(&writes)
+	testClient := test.NewMockTestClient(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet && req.URL.Path == "/users" {
+			usersRequests++
+		}
+		return recordWrite(req)
+	})
+
+	ctx := context.Background()
+
+	builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+	_, err := builder.directory.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = builder.Delete(ctx, &v2.ResourceId{
+		ResourceType: workspaceResourceType.Id,
+		Resource:     "TestWorkspace01",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// PandaDoc workspaces can't be removed, they are deactivated.
+	expected := []string{"POST /workspaces/TestWorkspace01/deactivate"}
+	if fmt.Sprint(writes) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected requests: got %v, want %v", writes, expected)
+	}
+
+	// The members of the deactivated workspace are listed again.
+	_, err = builder.directory.Users(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if usersRequests != 2 {
+		t.Errorf("Expected 2 users requests, got %d", usersRequests)
+	}
+}
+
+func TestWorkspaceBuilder_EntitlementGrantableToUsers(t *testing.T) {
+	testClient := test.NewTestClient(nil, nil)
+
+	ctx := context.Background()
+
+	builder := newWorkspaceBuilder(testClient, newDirectory(testClient), "Member")
+
+	workspaceResource, err := parseIntoWorkspaceResource(client.Workspace{ID: "testWorkspace01", Name: "test01"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entitlements, _, _, err := builder.Entitlements(ctx, workspaceResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Grant only accepts users, roles are members through the expansion of their grants.
+	if len(entitlements) != 1 || len(entitlements[0].GrantableTo) != 1 || entitlements[0].GrantableTo[0].Id != userResourceType.Id {
+		t.Errorf("Expected the membership to be grantable to users only, got %v", entitlements)
+	}
+}
@@ -1,9 +1,13 @@
 package test
 
 import (
+	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/conductorone/baton-panda-doc/pkg/client"
@@ -46,6 +50,44 @@ func NewTestClient(response *http.Response, err error) *client.PandaDocClient {
 	return client.NewClient(baseHttpClient)
 }
 
+// Helper function to create a test client whose responses are built by roundTrip.
+func NewMockTestClient(roundTrip func(*http.Request) (*http.Response, error)) *client.PandaDocClient {
+	transport := &MockRoundTripper{}
+	transport.SetRoundTrip(roundTrip)
+	httpClient := &http.Client{Transport: transport}
+	baseHttpClient := uhttp.NewBaseHttpClient(httpClient)
+	return client.NewClient(baseHttpClient)
+}
+
+// PagedRoundTrip serves items the way PandaDoc list endpoints do, honoring the page and count query parameters.
+// Every request is counted in requests.
+func PagedRoundTrip(items []any, requests *int) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		*requests++
+
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		count, _ := strconv.Atoi(req.URL.Query().Get("count"))
+		start := min((page-1)*count, len(items))
+		end := min(start+count, len(items))
+
+		body, err := json.Marshal(map[string]any{
+			"results": items[start:end],
+			"total":   len(items),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(string(body))),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	}
+}
+
 func ReadFile(fileName string) string {
 	data, err := os.ReadFile("../../test/mock_responses/" + fileName)
 	if err != nil {